@@ -33,6 +33,7 @@ func main() {
 	cfg.AddFlags()
 
 	flag.Parse()
+	cfg.Complete()
 
 	m, err := manager.New(cfg)
 	if err != nil {
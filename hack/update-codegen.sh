@@ -29,5 +29,5 @@ trap EXIT SIGINT SIGTERM
 chmod +x ${CODEGEN_PKG}/generate-groups.sh
 ${CODEGEN_PKG}/generate-groups.sh all \
   tkestack.io/volume-decorator/pkg/generated tkestack.io/volume-decorator/pkg/apis \
-  storage:v1 \
+  storage:v1,v2 \
   --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.go.txt
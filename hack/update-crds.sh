@@ -0,0 +1,60 @@
+#!/bin/bash
+
+# Copyright 2019 THL A29 Limited, a Tencent company.
+
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# 	http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(dirname "${BASH_SOURCE[0]}")/..
+
+CONTROLLER_GEN_VERSION=v0.3.0
+CRD_DIR="${SCRIPT_ROOT}/deploy/kubernetes/crds"
+CRD_GO="${SCRIPT_ROOT}/pkg/manager/zz_generated.crds.go"
+
+# Install controller-gen outside of the module so go.mod is left untouched.
+if ! command -v controller-gen >/dev/null 2>&1 || \
+  [[ "$(controller-gen --version)" != "Version: ${CONTROLLER_GEN_VERSION}" ]]; then
+  tmp_dir=$(mktemp -d)
+  trap 'rm -rf "${tmp_dir}"' EXIT
+  (cd "${tmp_dir}" && go mod init tmp >/dev/null 2>&1 && \
+    GO111MODULE=on go get "sigs.k8s.io/controller-tools/cmd/controller-gen@${CONTROLLER_GEN_VERSION}")
+fi
+CONTROLLER_GEN=$(command -v controller-gen || echo "$(go env GOPATH)/bin/controller-gen")
+
+rm -f "${CRD_DIR}"/*.yaml
+"${CONTROLLER_GEN}" crd:crdVersions=v1 paths=./pkg/apis/... output:crd:dir="${CRD_DIR}"
+
+# Embed the manifests so the manager can install them with --create-crd.
+{
+  cat "${SCRIPT_ROOT}/hack/boilerplate.go.txt"
+  echo
+  echo "// Code generated by hack/update-crds.sh. DO NOT EDIT."
+  echo
+  echo "package manager"
+  echo
+  echo "// crdManifests are the CRDs generated from pkg/apis."
+  echo "var crdManifests = []string{"
+  for f in "${CRD_DIR}"/*.yaml; do
+    if grep -q '`' "${f}"; then
+      echo "${f} contains a backtick and can not be embedded" >&2
+      exit 1
+    fi
+    echo "	\`$(cat "${f}")"
+    echo "\`,"
+  done
+  echo "}"
+} > "${CRD_GO}"
+gofmt -w "${CRD_GO}"
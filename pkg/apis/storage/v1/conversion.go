@@ -0,0 +1,249 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"tkestack.io/volume-decorator/pkg/apis/storage"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// v2 is the hub version of PersistentVolumeClaimRuntime, all conversions
+// between served versions go through it. Fields only exist in v2, for
+// example Spec.Creator and MountedNode.NodeName, are kept as JSON in the
+// hubDataAnnotation of the v1 object, so that they survive an update made
+// by a v1 client.
+
+// hubDataAnnotation is the annotation of v1 objects keeping the fields can't be represented in v1.
+const hubDataAnnotation = storage.GroupName + "/v2-data"
+
+// hubData is the data of a v2 PersistentVolumeClaimRuntime lost in v1.
+type hubData struct {
+	Spec               storagev2.PersistentVolumeClaimRuntimeSpec `json:"spec,omitempty"`
+	CapacityBytes      int64                                      `json:"capacityBytes,omitempty"`
+	InodesUsed         int64                                      `json:"inodesUsed,omitempty"`
+	InodesTotal        int64                                      `json:"inodesTotal,omitempty"`
+	UtilizationPercent int32                                      `json:"utilizationPercent,omitempty"`
+	UsageHistory       []storagev2.UsageSample                    `json:"usageHistory,omitempty"`
+	GrowthBytesPerDay  int64                                      `json:"growthBytesPerDay,omitempty"`
+	FullAt             *metav1.Time                               `json:"fullAt,omitempty"`
+	// MountedNodes are matched with the v1 ones by address.
+	MountedNodes []storagev2.MountedNode `json:"mountedNodes,omitempty"`
+	// WorkloadPods are the pods of workloads, keyed by storagev2.WorkloadKey.
+	WorkloadPods      map[string][]storagev2.ConsumingPod `json:"workloadPods,omitempty"`
+	BackendAttributes map[string]string                   `json:"backendAttributes,omitempty"`
+}
+
+// ConvertTo converts this PersistentVolumeClaimRuntime to the hub version.
+func (src *PersistentVolumeClaimRuntime) ConvertTo(dst *storagev2.PersistentVolumeClaimRuntime) {
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = storagev2.SchemeGroupVersion.String()
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	data := popHubData(&dst.ObjectMeta)
+	dst.Spec = data.Spec
+
+	in, out := src.Status.DeepCopy(), &dst.Status
+	if src.LegacySpec != nil {
+		migrateLegacySpec(src.LegacySpec.DeepCopy(), in)
+	}
+	*out = storagev2.PersistentVolumeClaimRuntimeStatus{
+		ObservedGeneration: in.ObservedGeneration,
+		UsageBytes:         in.UsageBytes,
+		CapacityBytes:      data.CapacityBytes,
+		InodesUsed:         data.InodesUsed,
+		InodesTotal:        data.InodesTotal,
+		UtilizationPercent: data.UtilizationPercent,
+		UsageHistory:       data.UsageHistory,
+		GrowthBytesPerDay:  data.GrowthBytesPerDay,
+		FullAt:             data.FullAt,
+		BackendAttributes:  data.BackendAttributes,
+		LastUpdated: storagev2.RuntimeTimestamps{
+			Statuses:     in.LastUpdated.Statuses,
+			Workloads:    in.LastUpdated.Workloads,
+			UsageBytes:   in.LastUpdated.UsageBytes,
+			MountedNodes: in.LastUpdated.MountedNodes,
+		},
+	}
+	for _, s := range in.Statuses {
+		out.Statuses = append(out.Statuses, storagev2.PersistentVolumeClaimStatus(s))
+	}
+	for _, c := range in.Conditions {
+		out.Conditions = append(out.Conditions, storagev2.PersistentVolumeClaimRuntimeCondition{
+			Type:               storagev2.PersistentVolumeClaimRuntimeConditionType(c.Type),
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	if len(in.Workloads) > 0 {
+		out.Workloads = make(map[string]storagev2.Workload, len(in.Workloads))
+		for _, w := range in.Workloads {
+			workload := storagev2.Workload{
+				ObjectReference: w.ObjectReference,
+				ReadOnly:        w.ReadOnly,
+				Replicas:        w.Replicas,
+				Timestamp:       w.Timestamp,
+			}
+			key := storagev2.WorkloadKey(&workload)
+			workload.Pods = data.WorkloadPods[key]
+			out.Workloads[key] = workload
+		}
+	}
+	nodes := make(map[string]storagev2.MountedNode, len(data.MountedNodes))
+	for _, node := range data.MountedNodes {
+		nodes[node.Address] = node
+	}
+	for _, address := range in.MountedNodes {
+		node, exist := nodes[address]
+		if !exist {
+			node = storagev2.MountedNode{Address: address}
+		}
+		out.MountedNodes = append(out.MountedNodes, node)
+	}
+}
+
+// ConvertFrom converts from the hub version to this PersistentVolumeClaimRuntime.
+func (dst *PersistentVolumeClaimRuntime) ConvertFrom(src *storagev2.PersistentVolumeClaimRuntime) {
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = SchemeGroupVersion.String()
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dst.Spec = PersistentVolumeClaimRuntimeSpec{}
+	dst.LegacySpec = nil
+
+	in, out := src.Status.DeepCopy(), &dst.Status
+	data := &hubData{
+		Spec:               *src.Spec.DeepCopy(),
+		CapacityBytes:      in.CapacityBytes,
+		InodesUsed:         in.InodesUsed,
+		InodesTotal:        in.InodesTotal,
+		UtilizationPercent: in.UtilizationPercent,
+		UsageHistory:       in.UsageHistory,
+		GrowthBytesPerDay:  in.GrowthBytesPerDay,
+		FullAt:             in.FullAt,
+		BackendAttributes:  in.BackendAttributes,
+	}
+	*out = PersistentVolumeClaimRuntimeStatus{
+		ObservedGeneration: in.ObservedGeneration,
+		UsageBytes:         in.UsageBytes,
+		LastUpdated: RuntimeTimestamps{
+			Statuses:     in.LastUpdated.Statuses,
+			Workloads:    in.LastUpdated.Workloads,
+			UsageBytes:   in.LastUpdated.UsageBytes,
+			MountedNodes: in.LastUpdated.MountedNodes,
+		},
+	}
+	for _, s := range in.Statuses {
+		out.Statuses = append(out.Statuses, PersistentVolumeClaimStatus(s))
+	}
+	for _, c := range in.Conditions {
+		out.Conditions = append(out.Conditions, PersistentVolumeClaimRuntimeCondition{
+			Type:               PersistentVolumeClaimRuntimeConditionType(c.Type),
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	keys := make([]string, 0, len(in.Workloads))
+	for key := range in.Workloads {
+		keys = append(keys, key)
+	}
+	// Keep the order stable, so that a conversion won't look like a change.
+	sort.Strings(keys)
+	for _, key := range keys {
+		w := in.Workloads[key]
+		if len(w.Pods) > 0 {
+			if data.WorkloadPods == nil {
+				data.WorkloadPods = make(map[string][]storagev2.ConsumingPod)
+			}
+			data.WorkloadPods[key] = w.Pods
+		}
+		out.Workloads = append(out.Workloads, Workload{
+			ObjectReference: w.ObjectReference,
+			ReadOnly:        w.ReadOnly,
+			Replicas:        w.Replicas,
+			Timestamp:       w.Timestamp,
+		})
+	}
+	for _, node := range in.MountedNodes {
+		out.MountedNodes = append(out.MountedNodes, node.Address)
+		if node != (storagev2.MountedNode{Address: node.Address}) {
+			data.MountedNodes = append(data.MountedNodes, node)
+		}
+	}
+	pushHubData(&dst.ObjectMeta, data)
+}
+
+// migrateLegacySpec fills the runtime information recorded in spec by old releases into status. An
+// object is written in v2 without the legacy spec since its first update, so only the empty fields
+// of an object never updated by the decorator after upgrading are filled.
+func migrateLegacySpec(legacy *LegacyPersistentVolumeClaimRuntimeSpec, status *PersistentVolumeClaimRuntimeStatus) {
+	if len(status.Statuses) == 0 {
+		status.Statuses = legacy.Statuses
+	}
+	if len(status.Workloads) == 0 {
+		status.Workloads = legacy.Workloads
+	}
+	if status.UsageBytes == 0 {
+		status.UsageBytes = legacy.UsageBytes
+	}
+	if len(status.MountedNodes) == 0 {
+		status.MountedNodes = legacy.MountedNodes
+	}
+}
+
+// pushHubData saves data to the hubDataAnnotation of meta, the annotation is removed if there is nothing to keep.
+func pushHubData(meta *metav1.ObjectMeta, data *hubData) {
+	delete(meta.Annotations, hubDataAnnotation)
+	if reflect.DeepEqual(data, &hubData{}) {
+		if len(meta.Annotations) == 0 {
+			meta.Annotations = nil
+		}
+		return
+	}
+	// The data only consists of plain API types, it can always be marshaled.
+	raw, _ := json.Marshal(data)
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[hubDataAnnotation] = string(raw)
+}
+
+// popHubData removes the hubDataAnnotation from meta and returns the data in it.
+// A malformed annotation is ignored, the data in it is lost like before it was introduced.
+func popHubData(meta *metav1.ObjectMeta) *hubData {
+	data := &hubData{}
+	raw, exist := meta.Annotations[hubDataAnnotation]
+	if !exist {
+		return data
+	}
+	delete(meta.Annotations, hubDataAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	if err := json.Unmarshal([]byte(raw), data); err != nil {
+		return &hubData{}
+	}
+	return data
+}
@@ -0,0 +1,222 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+// now is truncated to seconds, since it is kept in JSON.
+var now = metav1.NewTime(time.Now().Truncate(time.Second))
+
+func newHubPVCR() *storagev2.PersistentVolumeClaimRuntime {
+	replicas := int32(2)
+	fullAt := metav1.NewTime(now.Add(time.Hour * 24))
+	workload := storagev2.Workload{
+		ObjectReference: corev1.ObjectReference{
+			Kind: "Deployment", Namespace: "default", Name: "web", UID: "uid-web"},
+		Replicas:  &replicas,
+		Timestamp: &now,
+		Pods: []storagev2.ConsumingPod{{
+			Name: "web-0", NodeName: "node-1", Phase: corev1.PodRunning, Containers: []string{"nginx"}}},
+	}
+	job := storagev2.Workload{
+		ObjectReference: corev1.ObjectReference{Kind: "Job", Namespace: "default", Name: "backup", UID: "uid-job"},
+		ReadOnly:        true,
+		Timestamp:       &now,
+	}
+	return &storagev2.PersistentVolumeClaimRuntime{
+		TypeMeta: metav1.TypeMeta{APIVersion: storagev2.SchemeGroupVersion.String(), Kind: "PersistentVolumeClaimRuntime"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "data",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: storagev2.PersistentVolumeClaimRuntimeSpec{
+			Creator:     &storagev2.UserInfo{Username: "alice", UID: "1", Groups: []string{"dev"}},
+			OwnerLabels: map[string]string{"team": "storage"},
+		},
+		Status: storagev2.PersistentVolumeClaimRuntimeStatus{
+			ObservedGeneration: 3,
+			Statuses:           []storagev2.PersistentVolumeClaimStatus{storagev2.ClaimStatusInUse},
+			Conditions: []storagev2.PersistentVolumeClaimRuntimeCondition{{
+				Type:               storagev2.RuntimeConditionInUse,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: now,
+				Reason:             "WorkloadsAttached",
+			}},
+			Workloads: map[string]storagev2.Workload{
+				storagev2.WorkloadKey(&workload): workload,
+				storagev2.WorkloadKey(&job):      job,
+			},
+			UsageBytes:         1 << 30,
+			CapacityBytes:      10 << 30,
+			InodesUsed:         100,
+			InodesTotal:        1000,
+			UtilizationPercent: 10,
+			UsageHistory:       []storagev2.UsageSample{{Timestamp: now, UsedBytes: 1 << 30}},
+			GrowthBytesPerDay:  1 << 20,
+			FullAt:             &fullAt,
+			MountedNodes: []storagev2.MountedNode{
+				{NodeName: "node-1", Address: "10.0.0.1", AccessMode: "ReadWrite", FirstSeen: &now, LastSeen: &now},
+				{Address: "10.0.0.2"},
+			},
+			BackendAttributes: map[string]string{"pool": "rbd"},
+			LastUpdated:       storagev2.RuntimeTimestamps{Statuses: &now, UsageBytes: &now},
+		},
+	}
+}
+
+func TestConvertHubRoundTrip(t *testing.T) {
+	for name, hub := range map[string]*storagev2.PersistentVolumeClaimRuntime{
+		"full":  newHubPVCR(),
+		"empty": {ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			v1 := &PersistentVolumeClaimRuntime{}
+			v1.ConvertFrom(hub)
+			got := &storagev2.PersistentVolumeClaimRuntime{}
+			v1.ConvertTo(got)
+
+			hub.APIVersion = storagev2.SchemeGroupVersion.String()
+			if !equality.Semantic.DeepEqual(hub, got) {
+				t.Errorf("Round trip changed the object: %s", diff.ObjectReflectDiff(hub, got))
+			}
+		})
+	}
+}
+
+func TestConvertSpokeRoundTrip(t *testing.T) {
+	replicas := int32(1)
+	spoke := &PersistentVolumeClaimRuntime{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "PersistentVolumeClaimRuntime"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"},
+		Status: PersistentVolumeClaimRuntimeStatus{
+			ObservedGeneration: 1,
+			Statuses:           []PersistentVolumeClaimStatus{ClaimStatusInUse, ClaimStatusExpanding},
+			Conditions: []PersistentVolumeClaimRuntimeCondition{{
+				Type:               RuntimeConditionExpanding,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: now,
+			}},
+			Workloads: []Workload{
+				{ObjectReference: corev1.ObjectReference{Kind: "StatefulSet", Name: "db", UID: "uid-a"}, Replicas: &replicas},
+				{ObjectReference: corev1.ObjectReference{Kind: "Deployment", Name: "web", UID: "uid-b"}, Timestamp: &now},
+			},
+			UsageBytes:   1024,
+			MountedNodes: []string{"10.0.0.1", "10.0.0.2"},
+			LastUpdated:  RuntimeTimestamps{Workloads: &now},
+		},
+	}
+
+	hub := &storagev2.PersistentVolumeClaimRuntime{}
+	spoke.ConvertTo(hub)
+	got := &PersistentVolumeClaimRuntime{}
+	got.ConvertFrom(hub)
+
+	if !equality.Semantic.DeepEqual(spoke, got) {
+		t.Errorf("Round trip changed the object: %s", diff.ObjectReflectDiff(spoke, got))
+	}
+}
+
+func TestConvertKeepsHubDataOnSpokeUpdate(t *testing.T) {
+	hub := newHubPVCR()
+	spoke := &PersistentVolumeClaimRuntime{}
+	spoke.ConvertFrom(hub)
+
+	// A v1 client drops the job and the second node, and sets a new status.
+	spoke.Status.Workloads = spoke.Status.Workloads[1:]
+	spoke.Status.MountedNodes = spoke.Status.MountedNodes[:1]
+	spoke.Status.Statuses = []PersistentVolumeClaimStatus{ClaimStatusAvailable, ClaimStatusInUse}
+	got := &storagev2.PersistentVolumeClaimRuntime{}
+	spoke.ConvertTo(got)
+
+	expected := hub.DeepCopy()
+	delete(expected.Status.Workloads, "uid-job")
+	expected.Status.MountedNodes = expected.Status.MountedNodes[:1]
+	expected.Status.Statuses = []storagev2.PersistentVolumeClaimStatus{
+		storagev2.ClaimStatusAvailable, storagev2.ClaimStatusInUse}
+	if !equality.Semantic.DeepEqual(expected, got) {
+		t.Errorf("Unexpected object: %s", diff.ObjectReflectDiff(expected, got))
+	}
+}
+
+func TestConvertIgnoresMalformedHubData(t *testing.T) {
+	spoke := &PersistentVolumeClaimRuntime{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Annotations: map[string]string{hubDataAnnotation: "{"}},
+		Status:     PersistentVolumeClaimRuntimeStatus{UsageBytes: 1},
+	}
+	got := &storagev2.PersistentVolumeClaimRuntime{}
+	spoke.ConvertTo(got)
+
+	if len(got.Annotations) != 0 {
+		t.Errorf("Annotations should be removed, got %v", got.Annotations)
+	}
+	if got.Status.UsageBytes != 1 {
+		t.Errorf("Expected usage 1, got %d", got.Status.UsageBytes)
+	}
+}
+
+func TestConvertMigratesLegacySpec(t *testing.T) {
+	// An object written by the releases before the status subresource.
+	legacy := []byte(`{
+		"apiVersion": "storage.tkestack.io/v1",
+		"kind": "PersistentVolumeClaimRuntime",
+		"metadata": {"namespace": "default", "name": "data"},
+		"Spec": {
+			"status": ["InUse"],
+			"workloads": [{"kind": "StatefulSet", "name": "db", "uid": "uid-db", "readOnly": false, "replicas": null, "timestamp": null}],
+			"usageBytes": 1024,
+			"mountedNodes": ["10.0.0.1"]
+		}
+	}`)
+	spoke := &PersistentVolumeClaimRuntime{}
+	if err := json.Unmarshal(legacy, spoke); err != nil {
+		t.Fatalf("Unmarshal legacy object failed: %v", err)
+	}
+	got := &storagev2.PersistentVolumeClaimRuntime{}
+	spoke.ConvertTo(got)
+
+	expected := storagev2.PersistentVolumeClaimRuntimeStatus{
+		Statuses: []storagev2.PersistentVolumeClaimStatus{storagev2.ClaimStatusInUse},
+		Workloads: map[string]storagev2.Workload{"uid-db": {
+			ObjectReference: corev1.ObjectReference{Kind: "StatefulSet", Name: "db", UID: "uid-db"}}},
+		UsageBytes:   1024,
+		MountedNodes: []storagev2.MountedNode{{Address: "10.0.0.1"}},
+	}
+	if !equality.Semantic.DeepEqual(expected, got.Status) {
+		t.Errorf("Unexpected status: %s", diff.ObjectReflectDiff(expected, got.Status))
+	}
+
+	// Fields collected after upgrading are not overwritten.
+	spoke.Status.Statuses = []PersistentVolumeClaimStatus{ClaimStatusAvailable}
+	spoke.ConvertTo(got)
+	if !equality.Semantic.DeepEqual(got.Status.Statuses, []storagev2.PersistentVolumeClaimStatus{
+		storagev2.ClaimStatusAvailable}) {
+		t.Errorf("Unexpected statuses: %v", got.Status.Statuses)
+	}
+}
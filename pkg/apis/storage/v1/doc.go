@@ -16,7 +16,7 @@
  */
 
 // +k8s:deepcopy-gen=package
-// +groupName=storage.k8s.io
+// +groupName=storage.tkestack.io
 
 // Package v1 is the v1 version of the API.
 package v1
@@ -23,6 +23,7 @@ import (
 )
 
 // PersistentVolumeClaimStatus is the status of a PVC/PV.
+// +kubebuilder:validation:Enum=Unknown;Creating;Expanding;Available;InUse;Lost;Deleting
 type PersistentVolumeClaimStatus string
 
 const (
@@ -43,23 +44,94 @@ const (
 	// TODO: Add explorer related status.
 )
 
+// PersistentVolumeClaimRuntimeConditionType is a valid value for PersistentVolumeClaimRuntimeCondition.Type.
+// +kubebuilder:validation:Enum=InUse;Expanding;Lost;BackendError;QuotaDrift;MountedNodesUnavailable;PodsUnavailable;UsageUnavailable;QuotaUnavailable
+type PersistentVolumeClaimRuntimeConditionType string
+
+const (
+	// RuntimeConditionInUse indicates the volume is used by some workloads or mounted on some nodes.
+	RuntimeConditionInUse PersistentVolumeClaimRuntimeConditionType = "InUse"
+	// RuntimeConditionExpanding indicates the volume is expanding.
+	RuntimeConditionExpanding PersistentVolumeClaimRuntimeConditionType = "Expanding"
+	// RuntimeConditionLost indicates the PV of the volume is missed.
+	RuntimeConditionLost PersistentVolumeClaimRuntimeConditionType = "Lost"
+	// RuntimeConditionBackendError indicates the runtime information cannot be collected from the storage backend.
+	// Deprecated: it was shared by all the collectors, which report their errors by the conditions below.
+	RuntimeConditionBackendError PersistentVolumeClaimRuntimeConditionType = "BackendError"
+	// RuntimeConditionMountedNodesUnavailable indicates the mounted nodes of the volume cannot be collected.
+	RuntimeConditionMountedNodesUnavailable PersistentVolumeClaimRuntimeConditionType = "MountedNodesUnavailable"
+	// RuntimeConditionPodsUnavailable indicates the pods using the volume cannot be collected.
+	RuntimeConditionPodsUnavailable PersistentVolumeClaimRuntimeConditionType = "PodsUnavailable"
+	// RuntimeConditionUsageUnavailable indicates the usage of the volume cannot be collected.
+	RuntimeConditionUsageUnavailable PersistentVolumeClaimRuntimeConditionType = "UsageUnavailable"
+	// RuntimeConditionQuotaUnavailable indicates the quota of the volume cannot be read or reconciled.
+	RuntimeConditionQuotaUnavailable PersistentVolumeClaimRuntimeConditionType = "QuotaUnavailable"
+	// RuntimeConditionQuotaDrift indicates the quota of the volume in the storage backend differs from its capacity.
+	RuntimeConditionQuotaDrift PersistentVolumeClaimRuntimeConditionType = "QuotaDrift"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=pvcr;pvcrs
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=".status.statuses[*]"
+// +kubebuilder:printcolumn:name="Usage",type=integer,JSONPath=".status.usageBytes"
+// +kubebuilder:printcolumn:name="Workloads",type=string,JSONPath=".status.workloads[*].name"
+// +kubebuilder:printcolumn:name="Nodes",type=string,JSONPath=".status.mountedNodes[*]"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
 
 // PersistentVolumeClaimRuntime is the runtime information of a PVC/PV.
 type PersistentVolumeClaimRuntime struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec PersistentVolumeClaimRuntimeSpec
+	Spec PersistentVolumeClaimRuntimeSpec `json:"spec,omitempty"`
+	// Runtime information collected by the decorator, it can
+	// only be written through the status subresource.
+	// +optional
+	Status PersistentVolumeClaimRuntimeStatus `json:"status,omitempty"`
+	// Runtime information recorded by the releases before the status subresource,
+	// it is moved to status when converted to v2. Deprecated, never set it.
+	// +optional
+	LegacySpec *LegacyPersistentVolumeClaimRuntimeSpec `json:"Spec,omitempty"`
 }
 
 // PersistentVolumeClaimRuntimeSpec is the spec for a PersistentVolumeClaimRuntime resource.
 type PersistentVolumeClaimRuntimeSpec struct {
+	//TODO: Add user related information.
+}
+
+// LegacyPersistentVolumeClaimRuntimeSpec is the runtime information recorded in spec by the
+// releases before the status subresource, it is only read to migrate the old objects.
+type LegacyPersistentVolumeClaimRuntimeSpec struct {
+	// Statuses of PersistentVolumeClaim.
+	// +optional
+	Statuses []PersistentVolumeClaimStatus `json:"status,omitempty"`
+	// Workloads mounted by.
+	// +optional
+	Workloads []Workload `json:"workloads,omitempty"`
+	// Usage in bytes.
+	// +optional
+	UsageBytes int64 `json:"usageBytes,omitempty"`
+	// Nodes which mount this volume.
+	// +optional
+	MountedNodes []string `json:"mountedNodes,omitempty"`
+}
+
+// PersistentVolumeClaimRuntimeStatus is the runtime information of a PersistentVolumeClaimRuntime resource.
+type PersistentVolumeClaimRuntimeStatus struct {
+	// The generation of the PersistentVolumeClaimRuntime observed by the decorator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// Current Statuses of PersistentVolumeClaim.
 	// PersistentVolumeClaim may have more than one status at a moment.
 	// For example, an InUse volume maybe also in Expanding status.
-	Statuses []PersistentVolumeClaimStatus `json:"status"`
+	// +optional
+	Statuses []PersistentVolumeClaimStatus `json:"statuses"`
+	// Current conditions of PersistentVolumeClaim.
+	// +optional
+	Conditions []PersistentVolumeClaimRuntimeCondition `json:"conditions,omitempty"`
 	// Workloads mounted by.
 	// +optional
 	Workloads []Workload `json:"workloads"`
@@ -69,8 +141,38 @@ type PersistentVolumeClaimRuntimeSpec struct {
 	// Nodes which mount this volume.
 	// +optional
 	MountedNodes []string `json:"mountedNodes"`
+	// Timestamps when the fields above were last refreshed.
+	// +optional
+	LastUpdated RuntimeTimestamps `json:"lastUpdated,omitempty"`
+}
 
-	//TODO: Add user related information.
+// PersistentVolumeClaimRuntimeCondition contains details about state of a PersistentVolumeClaimRuntime.
+type PersistentVolumeClaimRuntimeCondition struct {
+	// Type of the condition.
+	Type PersistentVolumeClaimRuntimeConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Unique, one-word, CamelCase reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RuntimeTimestamps records when each field of PersistentVolumeClaimRuntimeStatus was last refreshed.
+type RuntimeTimestamps struct {
+	// +optional
+	Statuses *metav1.Time `json:"statuses,omitempty"`
+	// +optional
+	Workloads *metav1.Time `json:"workloads,omitempty"`
+	// +optional
+	UsageBytes *metav1.Time `json:"usageBytes,omitempty"`
+	// +optional
+	MountedNodes *metav1.Time `json:"mountedNodes,omitempty"`
 }
 
 // Workload is the information of workloads used some volumes.
@@ -81,12 +183,15 @@ type Workload struct {
 	ReadOnly bool `json:"readOnly"`
 	// Replicas of this workload. Will be nil if we can't
 	// determine the replicas, for example: DaemonSet.
+	// +optional
 	Replicas *int32 `json:"replicas"`
 	// Timestamp when the workload added.
+	// +optional
 	Timestamp *metav1.Time `json:"timestamp"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
 
 // PersistentVolumeClaimRuntimeList is a list of PersistentVolumeClaimRuntime.
 type PersistentVolumeClaimRuntimeList struct {
@@ -25,12 +25,51 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LegacyPersistentVolumeClaimRuntimeSpec) DeepCopyInto(out *LegacyPersistentVolumeClaimRuntimeSpec) {
+	*out = *in
+	if in.Statuses != nil {
+		in, out := &in.Statuses, &out.Statuses
+		*out = make([]PersistentVolumeClaimStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]Workload, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MountedNodes != nil {
+		in, out := &in.MountedNodes, &out.MountedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LegacyPersistentVolumeClaimRuntimeSpec.
+func (in *LegacyPersistentVolumeClaimRuntimeSpec) DeepCopy() *LegacyPersistentVolumeClaimRuntimeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LegacyPersistentVolumeClaimRuntimeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaimRuntime) DeepCopyInto(out *PersistentVolumeClaimRuntime) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	if in.LegacySpec != nil {
+		in, out := &in.LegacySpec, &out.LegacySpec
+		*out = new(LegacyPersistentVolumeClaimRuntimeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -52,6 +91,23 @@ func (in *PersistentVolumeClaimRuntime) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimRuntimeCondition) DeepCopyInto(out *PersistentVolumeClaimRuntimeCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRuntimeCondition.
+func (in *PersistentVolumeClaimRuntimeCondition) DeepCopy() *PersistentVolumeClaimRuntimeCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimRuntimeCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaimRuntimeList) DeepCopyInto(out *PersistentVolumeClaimRuntimeList) {
 	*out = *in
@@ -87,12 +143,35 @@ func (in *PersistentVolumeClaimRuntimeList) DeepCopyObject() runtime.Object {
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaimRuntimeSpec) DeepCopyInto(out *PersistentVolumeClaimRuntimeSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRuntimeSpec.
+func (in *PersistentVolumeClaimRuntimeSpec) DeepCopy() *PersistentVolumeClaimRuntimeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimRuntimeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimRuntimeStatus) DeepCopyInto(out *PersistentVolumeClaimRuntimeStatus) {
 	*out = *in
 	if in.Statuses != nil {
 		in, out := &in.Statuses, &out.Statuses
 		*out = make([]PersistentVolumeClaimStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]PersistentVolumeClaimRuntimeCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Workloads != nil {
 		in, out := &in.Workloads, &out.Workloads
 		*out = make([]Workload, len(*in))
@@ -105,15 +184,48 @@ func (in *PersistentVolumeClaimRuntimeSpec) DeepCopyInto(out *PersistentVolumeCl
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRuntimeSpec.
-func (in *PersistentVolumeClaimRuntimeSpec) DeepCopy() *PersistentVolumeClaimRuntimeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRuntimeStatus.
+func (in *PersistentVolumeClaimRuntimeStatus) DeepCopy() *PersistentVolumeClaimRuntimeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PersistentVolumeClaimRuntimeSpec)
+	out := new(PersistentVolumeClaimRuntimeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuntimeTimestamps) DeepCopyInto(out *RuntimeTimestamps) {
+	*out = *in
+	if in.Statuses != nil {
+		in, out := &in.Statuses, &out.Statuses
+		*out = (*in).DeepCopy()
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = (*in).DeepCopy()
+	}
+	if in.UsageBytes != nil {
+		in, out := &in.UsageBytes, &out.UsageBytes
+		*out = (*in).DeepCopy()
+	}
+	if in.MountedNodes != nil {
+		in, out := &in.MountedNodes, &out.MountedNodes
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeTimestamps.
+func (in *RuntimeTimestamps) DeepCopy() *RuntimeTimestamps {
+	if in == nil {
+		return nil
+	}
+	out := new(RuntimeTimestamps)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v2
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// claimStatusConditions maps a PersistentVolumeClaimStatus to the condition reflects it.
+var claimStatusConditions = []struct {
+	conditionType PersistentVolumeClaimRuntimeConditionType
+	status        PersistentVolumeClaimStatus
+	reason        string
+}{
+	{RuntimeConditionInUse, ClaimStatusInUse, "WorkloadsAttached"},
+	{RuntimeConditionExpanding, ClaimStatusExpanding, "Resizing"},
+	{RuntimeConditionLost, ClaimStatusLost, "VolumeLost"},
+}
+
+// GetCondition returns the condition with the given type, or nil if not exist.
+func (s *PersistentVolumeClaimRuntimeStatus) GetCondition(
+	conditionType PersistentVolumeClaimRuntimeConditionType) *PersistentVolumeClaimRuntimeCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates a condition. LastTransitionTime is only
+// changed when the status of the condition changed.
+func (s *PersistentVolumeClaimRuntimeStatus) SetCondition(condition PersistentVolumeClaimRuntimeCondition) {
+	existing := s.GetCondition(condition.Type)
+	if existing == nil {
+		condition.LastTransitionTime = metav1.Now()
+		s.Conditions = append(s.Conditions, condition)
+		return
+	}
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+}
+
+// RemoveCondition removes the condition with the given type if exists.
+func (s *PersistentVolumeClaimRuntimeStatus) RemoveCondition(conditionType PersistentVolumeClaimRuntimeConditionType) {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			s.Conditions = append(s.Conditions[:i], s.Conditions[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetStatuses updates the statuses, and keeps the InUse, Expanding and Lost conditions consistent with them.
+func (s *PersistentVolumeClaimRuntimeStatus) SetStatuses(statuses []PersistentVolumeClaimStatus) {
+	if !statusesEqual(s.Statuses, statuses) {
+		now := metav1.Now()
+		s.Statuses = statuses
+		s.LastUpdated.Statuses = &now
+	}
+
+	for _, c := range claimStatusConditions {
+		condition := PersistentVolumeClaimRuntimeCondition{Type: c.conditionType, Status: corev1.ConditionFalse}
+		if HasStatus(statuses, c.status) {
+			condition.Status = corev1.ConditionTrue
+			condition.Reason = c.reason
+		}
+		s.SetCondition(condition)
+	}
+}
+
+// WorkloadKey returns the key of a workload in PersistentVolumeClaimRuntimeStatus.Workloads.
+func WorkloadKey(w *Workload) string {
+	if len(w.UID) > 0 {
+		return string(w.UID)
+	}
+	// Workloads recorded before they have an UID, should not happen in practice.
+	return fmt.Sprintf("%s/%s/%s", w.Kind, w.Namespace, w.Name)
+}
+
+// HasStatus returns true if status is one of statuses.
+func HasStatus(statuses []PersistentVolumeClaimStatus, status PersistentVolumeClaimStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// statusesEqual returns true if two status lists are equal.
+func statusesEqual(s1, s2 []PersistentVolumeClaimStatus) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			return false
+		}
+	}
+	return true
+}
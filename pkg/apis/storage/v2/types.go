@@ -0,0 +1,434 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersistentVolumeClaimStatus is the status of a PVC/PV.
+// +kubebuilder:validation:Enum=Unknown;Creating;Expanding;Available;InUse;Lost;Deleting
+type PersistentVolumeClaimStatus string
+
+const (
+	// ClaimStatusUnknown indicates cannot determine volume's status.
+	ClaimStatusUnknown PersistentVolumeClaimStatus = "Unknown"
+	// ClaimStatusCreating indicates the PV object is still creating.
+	ClaimStatusCreating PersistentVolumeClaimStatus = "Creating"
+	// ClaimStatusExpanding indicates the PVC is expanding.
+	ClaimStatusExpanding PersistentVolumeClaimStatus = "Expanding"
+	// ClaimStatusAvailable indicates the PVC is created and can be used by any workloads.
+	ClaimStatusAvailable PersistentVolumeClaimStatus = "Available"
+	// ClaimStatusInUse indicates the PVC is used by some workloads.
+	ClaimStatusInUse PersistentVolumeClaimStatus = "InUse"
+	// ClaimStatusLost indicates the PV is missed.
+	ClaimStatusLost PersistentVolumeClaimStatus = "Lost"
+	// ClaimStatusDeleting indicates the PVC is deleting.
+	ClaimStatusDeleting PersistentVolumeClaimStatus = "Deleting"
+	// TODO: Add explorer related status.
+)
+
+// PersistentVolumeClaimRuntimeConditionType is a valid value for PersistentVolumeClaimRuntimeCondition.Type.
+// +kubebuilder:validation:Enum=InUse;Expanding;Lost;BackendError;QuotaDrift;MountedNodesUnavailable;PodsUnavailable;UsageUnavailable;QuotaUnavailable
+type PersistentVolumeClaimRuntimeConditionType string
+
+const (
+	// RuntimeConditionInUse indicates the volume is used by some workloads or mounted on some nodes.
+	RuntimeConditionInUse PersistentVolumeClaimRuntimeConditionType = "InUse"
+	// RuntimeConditionExpanding indicates the volume is expanding.
+	RuntimeConditionExpanding PersistentVolumeClaimRuntimeConditionType = "Expanding"
+	// RuntimeConditionLost indicates the PV of the volume is missed.
+	RuntimeConditionLost PersistentVolumeClaimRuntimeConditionType = "Lost"
+	// RuntimeConditionBackendError indicates the runtime information cannot be collected from the storage backend.
+	// Deprecated: it was shared by all the collectors, which report their errors by the conditions below.
+	RuntimeConditionBackendError PersistentVolumeClaimRuntimeConditionType = "BackendError"
+	// RuntimeConditionMountedNodesUnavailable indicates the mounted nodes of the volume cannot be collected.
+	RuntimeConditionMountedNodesUnavailable PersistentVolumeClaimRuntimeConditionType = "MountedNodesUnavailable"
+	// RuntimeConditionPodsUnavailable indicates the pods using the volume cannot be collected.
+	RuntimeConditionPodsUnavailable PersistentVolumeClaimRuntimeConditionType = "PodsUnavailable"
+	// RuntimeConditionUsageUnavailable indicates the usage of the volume cannot be collected.
+	RuntimeConditionUsageUnavailable PersistentVolumeClaimRuntimeConditionType = "UsageUnavailable"
+	// RuntimeConditionQuotaUnavailable indicates the quota of the volume cannot be read or reconciled.
+	RuntimeConditionQuotaUnavailable PersistentVolumeClaimRuntimeConditionType = "QuotaUnavailable"
+	// RuntimeConditionQuotaDrift indicates the quota of the volume in the storage backend differs from its capacity.
+	RuntimeConditionQuotaDrift PersistentVolumeClaimRuntimeConditionType = "QuotaDrift"
+)
+
+// MountAccessMode is how a node accesses a mounted volume.
+// +kubebuilder:validation:Enum=ReadWrite;ReadOnly;Unknown
+type MountAccessMode string
+
+const (
+	// MountAccessReadWrite indicates the node holds the write lock of the volume.
+	MountAccessReadWrite MountAccessMode = "ReadWrite"
+	// MountAccessReadOnly indicates the node only watches the volume without holding its lock.
+	MountAccessReadOnly MountAccessMode = "ReadOnly"
+	// MountAccessUnknown indicates the storage backend doesn't report the access mode.
+	MountAccessUnknown MountAccessMode = "Unknown"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=pvcr;pvcrs
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=".status.statuses[*]"
+// +kubebuilder:printcolumn:name="Creator",type=string,JSONPath=".spec.creator.username",priority=1
+// +kubebuilder:printcolumn:name="Usage",type=integer,JSONPath=".status.usageBytes"
+// +kubebuilder:printcolumn:name="Capacity",type=integer,JSONPath=".status.capacityBytes"
+// +kubebuilder:printcolumn:name="Utilization",type=integer,JSONPath=".status.utilizationPercent"
+// +kubebuilder:printcolumn:name="Full At",type=date,JSONPath=".status.fullAt"
+// +kubebuilder:printcolumn:name="Workloads",type=string,JSONPath=".status.workloads[*].name"
+// +kubebuilder:printcolumn:name="Nodes",type=string,JSONPath=".status.mountedNodes[*].nodeName"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// PersistentVolumeClaimRuntime is the runtime information of a PVC/PV.
+type PersistentVolumeClaimRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PersistentVolumeClaimRuntimeSpec `json:"spec,omitempty"`
+	// Runtime information collected by the decorator, it can
+	// only be written through the status subresource.
+	// +optional
+	Status PersistentVolumeClaimRuntimeStatus `json:"status,omitempty"`
+}
+
+// PersistentVolumeClaimRuntimeSpec is the spec for a PersistentVolumeClaimRuntime resource.
+type PersistentVolumeClaimRuntimeSpec struct {
+	// The user who created the PVC, nil if the PVC was created
+	// without the claim admission enabled.
+	// +optional
+	Creator *UserInfo `json:"creator,omitempty"`
+	// Ownership labels, such as team and cost center, inherited from the namespace of the PVC.
+	// +optional
+	OwnerLabels map[string]string `json:"ownerLabels,omitempty"`
+}
+
+// UserInfo is the identity of a user reported by the apiserver.
+type UserInfo struct {
+	// Name of the user.
+	Username string `json:"username"`
+	// UID of the user.
+	// +optional
+	UID string `json:"uid,omitempty"`
+	// Groups the user belongs to.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// PersistentVolumeClaimRuntimeStatus is the runtime information of a PersistentVolumeClaimRuntime resource.
+type PersistentVolumeClaimRuntimeStatus struct {
+	// The generation of the PersistentVolumeClaimRuntime observed by the decorator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Current Statuses of PersistentVolumeClaim.
+	// PersistentVolumeClaim may have more than one status at a moment.
+	// For example, an InUse volume maybe also in Expanding status.
+	// +optional
+	Statuses []PersistentVolumeClaimStatus `json:"statuses"`
+	// Current conditions of PersistentVolumeClaim.
+	// +optional
+	Conditions []PersistentVolumeClaimRuntimeCondition `json:"conditions,omitempty"`
+	// Workloads mounted by, keyed by the UID of the workload.
+	// +optional
+	Workloads map[string]Workload `json:"workloads,omitempty"`
+	// Current usage in bytes.
+	// +optional
+	UsageBytes int64 `json:"usageBytes,omitempty"`
+	// Capacity of the volume in bytes.
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// Current used inodes.
+	// +optional
+	InodesUsed int64 `json:"inodesUsed,omitempty"`
+	// Total inodes of the volume.
+	// +optional
+	InodesTotal int64 `json:"inodesTotal,omitempty"`
+	// Percentage of UsageBytes in CapacityBytes.
+	// +optional
+	UtilizationPercent int32 `json:"utilizationPercent,omitempty"`
+	// Recent usage samples, from the oldest to the newest.
+	// +optional
+	UsageHistory []UsageSample `json:"usageHistory,omitempty"`
+	// Growth rate of UsageBytes computed from UsageHistory, in bytes per day.
+	// +optional
+	GrowthBytesPerDay int64 `json:"growthBytesPerDay,omitempty"`
+	// Estimated time when the volume will be full, nil if it is not growing.
+	// +optional
+	FullAt *metav1.Time `json:"fullAt,omitempty"`
+	// Nodes which mount this volume.
+	// +optional
+	MountedNodes []MountedNode `json:"mountedNodes,omitempty"`
+	// Attributes reported by the storage backend, for example the type and zone of a CBS disk.
+	// +optional
+	BackendAttributes map[string]string `json:"backendAttributes,omitempty"`
+	// Timestamps when the fields above were last refreshed.
+	// +optional
+	LastUpdated RuntimeTimestamps `json:"lastUpdated,omitempty"`
+}
+
+// PersistentVolumeClaimRuntimeCondition contains details about state of a PersistentVolumeClaimRuntime.
+type PersistentVolumeClaimRuntimeCondition struct {
+	// Type of the condition.
+	Type PersistentVolumeClaimRuntimeConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Unique, one-word, CamelCase reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RuntimeTimestamps records when each field of PersistentVolumeClaimRuntimeStatus was last refreshed.
+type RuntimeTimestamps struct {
+	// +optional
+	Statuses *metav1.Time `json:"statuses,omitempty"`
+	// +optional
+	Workloads *metav1.Time `json:"workloads,omitempty"`
+	// +optional
+	UsageBytes *metav1.Time `json:"usageBytes,omitempty"`
+	// +optional
+	MountedNodes *metav1.Time `json:"mountedNodes,omitempty"`
+}
+
+// UsageSample is a sample of the usage of a volume.
+type UsageSample struct {
+	// Time when the sample is collected.
+	Timestamp metav1.Time `json:"timestamp"`
+	// Used bytes at Timestamp.
+	UsedBytes int64 `json:"usedBytes"`
+}
+
+// MountedNode is a record of a node which mounts a volume.
+type MountedNode struct {
+	// Name of the node, empty if the address can't be resolved to a node.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// Client address the volume is mounted from, reported by the storage backend.
+	Address string `json:"address"`
+	// How the node accesses the volume.
+	// +optional
+	AccessMode MountAccessMode `json:"accessMode,omitempty"`
+	// Time when the mount was first seen.
+	// +optional
+	FirstSeen *metav1.Time `json:"firstSeen,omitempty"`
+	// Time when the mount was last seen.
+	// +optional
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+}
+
+// Workload is the information of workloads used some volumes.
+type Workload struct {
+	corev1.ObjectReference `json:",inline"`
+
+	// The volume is used by this workload as read only.
+	ReadOnly bool `json:"readOnly"`
+	// Replicas of this workload. Will be nil if we can't
+	// determine the replicas, for example: DaemonSet.
+	// +optional
+	Replicas *int32 `json:"replicas"`
+	// Timestamp when the workload added.
+	// +optional
+	Timestamp *metav1.Time `json:"timestamp"`
+	// Pods of this workload which consume the volume.
+	// +optional
+	Pods []ConsumingPod `json:"pods,omitempty"`
+}
+
+// ConsumingPod is a pod which consumes a volume.
+type ConsumingPod struct {
+	// Name of the pod.
+	Name string `json:"name"`
+	// Name of the node the pod is scheduled to.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// Phase of the pod.
+	// +optional
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+	// Names of the containers which mount the volume.
+	// +optional
+	Containers []string `json:"containers,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// PersistentVolumeClaimRuntimeList is a list of PersistentVolumeClaimRuntime.
+type PersistentVolumeClaimRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PersistentVolumeClaimRuntime `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=nsr;nsrs
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Volumes",type=integer,JSONPath=".status.volumeCount"
+// +kubebuilder:printcolumn:name="Requested",type=integer,JSONPath=".status.requestedBytes"
+// +kubebuilder:printcolumn:name="Usage",type=integer,JSONPath=".status.usageBytes"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// NamespaceStorageRuntime is a summary of all PersistentVolumeClaimRuntimes in a namespace,
+// it is named after the namespace.
+type NamespaceStorageRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Summary collected by the decorator, it can only be
+	// written through the status subresource.
+	// +optional
+	Status NamespaceStorageRuntimeStatus `json:"status,omitempty"`
+}
+
+// NamespaceStorageRuntimeStatus is the summary of volumes in a namespace.
+type NamespaceStorageRuntimeStatus struct {
+	// Count of volumes in the namespace.
+	VolumeCount int32 `json:"volumeCount"`
+	// Total capacity requested by the PVCs.
+	RequestedBytes int64 `json:"requestedBytes"`
+	// Total capacity of the volumes reported by the storage backends.
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// Total real usage of the volumes.
+	UsageBytes int64 `json:"usageBytes"`
+	// Count of volumes in each status, a volume may be counted in more than one status.
+	// +optional
+	StatusCounts map[string]int32 `json:"statusCounts,omitempty"`
+	// Volumes using the most bytes, from the largest to the smallest.
+	// +optional
+	TopConsumers []VolumeConsumer `json:"topConsumers,omitempty"`
+	// Summary of volumes of each StorageClass.
+	// +optional
+	StorageClasses []StorageClassSummary `json:"storageClasses,omitempty"`
+	// Last time the summary was refreshed.
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// VolumeConsumer is the usage of a volume.
+type VolumeConsumer struct {
+	// Name of the PVC.
+	Name string `json:"name"`
+	// Real usage of the volume.
+	UsageBytes int64 `json:"usageBytes"`
+	// Capacity of the volume.
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// Percentage of UsageBytes in CapacityBytes.
+	// +optional
+	UtilizationPercent int32 `json:"utilizationPercent,omitempty"`
+}
+
+// StorageClassSummary is the summary of volumes of a StorageClass.
+type StorageClassSummary struct {
+	// Name of the StorageClass, empty for volumes without StorageClass.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// Count of volumes.
+	VolumeCount int32 `json:"volumeCount"`
+	// Total capacity requested by the PVCs.
+	RequestedBytes int64 `json:"requestedBytes"`
+	// Total capacity of the volumes reported by the storage backends.
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// Total real usage of the volumes.
+	UsageBytes int64 `json:"usageBytes"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// NamespaceStorageRuntimeList is a list of NamespaceStorageRuntime.
+type NamespaceStorageRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NamespaceStorageRuntime `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=scr;scrs
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Volumes",type=integer,JSONPath=".status.volumeCount"
+// +kubebuilder:printcolumn:name="Provisioned",type=integer,JSONPath=".status.provisionedBytes"
+// +kubebuilder:printcolumn:name="Usage",type=integer,JSONPath=".status.usageBytes"
+// +kubebuilder:printcolumn:name="Workloads",type=integer,JSONPath=".status.workloadCount",priority=1
+// +kubebuilder:printcolumn:name="Pools",type=string,JSONPath=".status.pools",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+
+// StorageClassRuntime is a summary of all PersistentVolumeClaimRuntimes of a StorageClass,
+// it is named after the StorageClass.
+type StorageClassRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Summary collected by the decorator, it can only be
+	// written through the status subresource.
+	// +optional
+	Status StorageClassRuntimeStatus `json:"status,omitempty"`
+}
+
+// StorageClassRuntimeStatus is the summary of volumes of a StorageClass.
+type StorageClassRuntimeStatus struct {
+	// Count of volumes of the StorageClass.
+	VolumeCount int32 `json:"volumeCount"`
+	// Total capacity of the PVs provisioned.
+	ProvisionedBytes int64 `json:"provisionedBytes"`
+	// Total capacity of the volumes reported by the storage backends.
+	// +optional
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+	// Total real usage of the volumes.
+	UsageBytes int64 `json:"usageBytes"`
+	// Count of volumes in each status, a volume may be counted in more than one status.
+	// +optional
+	StatusCounts map[string]int32 `json:"statusCounts,omitempty"`
+	// Count of distinct workloads using the volumes.
+	// +optional
+	WorkloadCount int32 `json:"workloadCount,omitempty"`
+	// Backend pools the volumes are allocated from.
+	// +optional
+	Pools []string `json:"pools,omitempty"`
+	// Last time the summary was refreshed.
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// StorageClassRuntimeList is a list of StorageClassRuntime.
+type StorageClassRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StorageClassRuntime `json:"items"`
+}
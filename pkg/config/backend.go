@@ -0,0 +1,92 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// GenericBackend is the name of the backend serving volumes of any CSI driver
+	// from VolumeAttachments and the kubelet volume stats.
+	GenericBackend = "generic"
+	// UnmanagedBackend is the name of the backend which admits all workloads and collects nothing from the storage.
+	UnmanagedBackend = "unmanaged"
+)
+
+// BackendConfig maps CSI drivers and StorageClasses to backends.
+type BackendConfig struct {
+	// Backends are the backends besides the ones enabled by --volume-types, keyed by their names.
+	Backends map[string]Backend `json:"backends,omitempty"`
+	// Drivers maps CSI driver names to backend names.
+	Drivers map[string]string `json:"drivers,omitempty"`
+	// StorageClasses maps StorageClass names to backend names, it takes precedence over Drivers.
+	StorageClasses map[string]string `json:"storageClasses,omitempty"`
+	// DefaultBackend is the backend of volumes not mapped to any backend, GenericBackend if empty.
+	DefaultBackend string `json:"defaultBackend,omitempty"`
+}
+
+// Backend is a backend implementation with its settings.
+type Backend struct {
+	// Type is one of the volume types, e.g. csi-rbd.
+	Type string `json:"type"`
+	// Settings overrides the volume flags for this backend, keyed by flag names
+	// without the leading dashes, e.g. ceph-config-file.
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// LoadBackendConfig loads a BackendConfig from a YAML or JSON file, an empty config
+// is returned if path is empty.
+func LoadBackendConfig(path string) (*BackendConfig, error) {
+	config := &BackendConfig{}
+	if len(path) == 0 {
+		return config, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read backend config %s failed: %v", path, err)
+	}
+	data, err = yaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("convert backend config %s to json failed: %v", path, err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("decode backend config %s failed: %v", path, err)
+	}
+	return config, nil
+}
+
+// WithSettings returns a copy of c with settings applied, settings are parsed as the volume flags.
+func (c *VolumeConfig) WithSettings(settings map[string]string) (*VolumeConfig, error) {
+	config := &VolumeConfig{}
+	flags := flag.NewFlagSet("backend", flag.ContinueOnError)
+	config.addFlagsTo(flags)
+	// Start from the current values rather than the flag defaults.
+	*config = *c
+	for name, value := range settings {
+		if err := flags.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid setting %s: %v", name, err)
+		}
+	}
+	return config, nil
+}
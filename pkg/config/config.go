@@ -20,6 +20,7 @@ package config
 import (
 	"crypto/tls"
 	"flag"
+	"os"
 	"time"
 
 	"k8s.io/klog"
@@ -32,10 +33,21 @@ type Config struct {
 	VolumeConfig
 	Worker                  int
 	CreateCRD               bool
+	SyncCRDConversion       bool
+	MigrateStorageVersion   bool
+	UsageHistoryLength      int
+	UsageHistoryResolution  time.Duration
+	OwnerLabels             string
+	QuotaReconcileDryRun    bool
 	LeaderElection          bool
 	LeaderElectionNamespace string
 }
 
+// Complete fills the configurations read from the environment, it must be called after the flags parsed.
+func (c *Config) Complete() {
+	c.TencentCloudConfig.complete()
+}
+
 // AddFlags adds all configurations to the global flags.
 func (c *Config) AddFlags() {
 	c.WebhookConfig.AddFlags()
@@ -43,6 +55,19 @@ func (c *Config) AddFlags() {
 	c.VolumeConfig.AddFlags()
 	flag.IntVar(&c.Worker, "worker", 10, "Worker count")
 	flag.BoolVar(&c.CreateCRD, "create-crd", false, "Create the CRD when manager started")
+	flag.BoolVar(&c.SyncCRDConversion, "sync-crd-conversion", true,
+		"Point the conversion of PersistentVolumeClaimRuntime CRD to the webhook when manager started")
+	flag.BoolVar(&c.MigrateStorageVersion, "migrate-storage-version", false,
+		"Rewrite all PersistentVolumeClaimRuntimes in the storage version when manager started")
+	flag.IntVar(&c.UsageHistoryLength, "usage-history-length", 24,
+		"Max count of usage samples kept in a PersistentVolumeClaimRuntime, 0 means disable the usage history")
+	flag.DurationVar(&c.UsageHistoryResolution, "usage-history-resolution", time.Hour,
+		"Min interval between two usage samples kept in a PersistentVolumeClaimRuntime")
+	flag.StringVar(&c.OwnerLabels, "owner-labels", "team,cost-center",
+		"Comma separated namespace label keys inherited by PersistentVolumeClaimRuntimes as ownership labels")
+	flag.BoolVar(&c.QuotaReconcileDryRun, "quota-reconcile-dry-run", true,
+		"Only report the quotas of volumes differing from their capacity by the QuotaDrift condition, "+
+			"without growing them to the capacity")
 	flag.BoolVar(&c.LeaderElection, "leader-election", false, "Enable leader election.")
 	flag.StringVar(&c.LeaderElectionNamespace, "leader-election-namespace",
 		"kube-system", "Namespace where the leader election resource lives.")
@@ -56,10 +81,12 @@ type WebhookConfig struct {
 	CAFile            string
 	MutatingPath      string
 	ValidatingPath    string
+	ConversionPath    string
 	URL               string
 	ServiceName       string
 	ServiceNamespace  string
 	WorkloadAdmission bool
+	ClaimAdmission    bool
 }
 
 // AddFlags adds webhook related configurations to the global flags.
@@ -67,6 +94,10 @@ func (c *WebhookConfig) AddFlags() {
 	flag.StringVar(&c.Name, "webhook-name", "volume-decorator", "Name of the webhook")
 	flag.StringVar(&c.ValidatingPath, "workload-webhook-path",
 		"/tke/storage/workload", "Path of the workload webhook")
+	flag.StringVar(&c.MutatingPath, "claim-webhook-path",
+		"/tke/storage/claim", "Path of the PVC webhook")
+	flag.StringVar(&c.ConversionPath, "conversion-webhook-path",
+		"/tke/storage/conversion", "Path of the PersistentVolumeClaimRuntime conversion webhook")
 	flag.StringVar(&c.CertFile, "tls-cert-file", c.CertFile, ""+
 		"File containing the default x509 Certificate for HTTPS. (CA cert, if any, concatenated "+
 		"after server cert).")
@@ -80,6 +111,8 @@ func (c *WebhookConfig) AddFlags() {
 	flag.StringVar(&c.ServiceNamespace, "service-namespace", "kube-system",
 		"Namespace the webhook service running, will be used if the service running in the cluster")
 	flag.BoolVar(&c.WorkloadAdmission, "workload-admission", false, "Enable workload admission")
+	flag.BoolVar(&c.ClaimAdmission, "claim-admission", false,
+		"Enable PVC admission to record the creator of PVCs")
 }
 
 // TLSConfig returns the TLS config.
@@ -111,29 +144,106 @@ func (c *K8sConfig) AddFlags() {
 
 // VolumeConfig is a set of configurations about concrete volumes.
 type VolumeConfig struct {
-	Types string
+	Types             string
+	BackendConfigFile string
+	CommandRecordDir  string
+	CommandReplayDir  string
 	CephConfig
+	TencentCloudConfig
+	NFSConfig
 }
 
 // AddFlags adds volume related configurations to the global flags.
 func (c *VolumeConfig) AddFlags() {
-	flag.StringVar(&c.Types, "volume-types", "", "Volume types the cluster supported")
-	flag.StringVar(&c.CephConfig.ConfigFile, "ceph-config-file",
+	c.addFlagsTo(flag.CommandLine)
+}
+
+// addFlagsTo adds volume related configurations to a flag set.
+func (c *VolumeConfig) addFlagsTo(fs *flag.FlagSet) {
+	fs.StringVar(&c.Types, "volume-types", "", "Volume types the cluster supported")
+	fs.StringVar(&c.BackendConfigFile, "volume-backend-config", "",
+		"Path of a YAML or JSON file mapping CSI drivers and StorageClasses to backends")
+	fs.StringVar(&c.CommandRecordDir, "volume-command-record-dir", "",
+		"Dir to record the outputs of the rbd, ceph and other commands run for volumes, for replaying later")
+	fs.StringVar(&c.CommandReplayDir, "volume-command-replay-dir", "",
+		"Dir of the recorded command outputs served instead of running the commands, for debugging without a cluster")
+	fs.StringVar(&c.CephConfig.ConfigFile, "ceph-config-file",
 		"/etc/ceph/ceph.conf", "Path of ceph config file")
-	flag.StringVar(&c.CephConfig.KeryingFile, "ceph-keyring-file",
+	fs.StringVar(&c.CephConfig.KeryingFile, "ceph-keyring-file",
 		"/etc/ceph/ceph.client.admin.keyring", "Path of ceph admin keyring file")
-	flag.DurationVar(&c.CephConfig.MdsSessionListPeriod, "ceph-mds-session-list-period",
+	fs.StringVar(&c.CephConfig.Secret, "ceph-secret", "",
+		"Secret(namespace/name) holding the ceph credentials, it takes precedence over --ceph-keyring-file")
+	fs.DurationVar(&c.CephConfig.RBDUsagePeriod, "ceph-rbd-usage-period", time.Minute*5,
+		"Period between two consecutive rbd du operations of a pool")
+	fs.DurationVar(&c.CephConfig.RBDUsageMaxStaleness, "ceph-rbd-usage-max-staleness", time.Minute*15,
+		"Max age of the rbd usage reported, the usage of a pool not collected in time is reported as an error")
+	fs.StringVar(&c.CephConfig.MgrPrometheusURL, "ceph-mgr-prometheus-url", "",
+		"Metrics URL of the ceph mgr prometheus module, e.g. http://mgr:9283/metrics, to collect IO rates and clients")
+	fs.StringVar(&c.CephConfig.ClusterConfigFile, "ceph-cluster-config", "",
+		"Path of the ceph-csi cluster config, a JSON list of clusterIDs and their monitors")
+	fs.DurationVar(&c.CephConfig.MdsSessionListPeriod, "ceph-mds-session-list-period",
 		time.Second*30, "Period between two consecutive mds session list operations")
-	flag.StringVar(&c.CephConfig.CephFSRootPath, "cephfs-root-path", "/", "Path of cephfs root dir")
-	flag.StringVar(&c.CephConfig.CephFSRootMountPath, "cephfs-root-mount-path",
+	fs.StringVar(&c.CephConfig.CephFSRootPath, "cephfs-root-path", "/", "Path of cephfs root dir")
+	fs.StringVar(&c.CephConfig.CephFSRootMountPath, "cephfs-root-mount-path",
 		"/tmp/cephfs-root", "Local path to mount the cephfs root dir")
+	fs.BoolVar(&c.CephConfig.CephFSMountless, "cephfs-mountless", false,
+		"Read the cephfs usage from the mds and the subvolume info instead of mounting the root by ceph-fuse, "+
+			"so that no privilege is required")
+	fs.StringVar(&c.NFSConfig.RootMountPath, "nfs-root-mount-path",
+		"/tmp/nfs-root", "Local path to mount the nfs exports")
+	fs.StringVar(&c.NFSConfig.MountOptions, "nfs-mount-options",
+		"ro,soft,nolock", "Options to mount the nfs exports")
+	fs.StringVar(&c.TencentCloudConfig.Region, "tencentcloud-region", "", "Region of the Tencent Cloud CBS disks")
+	fs.StringVar(&c.TencentCloudConfig.CBSEndpoint, "tencentcloud-cbs-endpoint",
+		"cbs.tencentcloudapi.com", "Endpoint of the Tencent Cloud CBS API, https is used if no scheme given")
+	fs.StringVar(&c.TencentCloudConfig.CVMEndpoint, "tencentcloud-cvm-endpoint",
+		"cvm.tencentcloudapi.com", "Endpoint of the Tencent Cloud CVM API, https is used if no scheme given")
+	// The secrets are read from the environment by Complete, so that they are never printed as defaults.
+	fs.StringVar(&c.TencentCloudConfig.SecretID, "tencentcloud-secret-id", "",
+		"Secret ID of the Tencent Cloud API, defaults to $TENCENTCLOUD_SECRET_ID")
+	fs.StringVar(&c.TencentCloudConfig.SecretKey, "tencentcloud-secret-key", "",
+		"Secret key of the Tencent Cloud API, defaults to $TENCENTCLOUD_SECRET_KEY")
+	fs.DurationVar(&c.TencentCloudConfig.CacheTTL, "tencentcloud-cache-ttl", time.Minute,
+		"Duration the CBS disks and CVM instances are cached")
 }
 
 // CephConfig is a set of configurations used to manage ceph related volumes: CephRBD and CephFS.
 type CephConfig struct {
 	ConfigFile           string
 	KeryingFile          string
+	Secret               string
+	MgrPrometheusURL     string
+	ClusterConfigFile    string
 	MdsSessionListPeriod time.Duration
+	RBDUsagePeriod       time.Duration
+	RBDUsageMaxStaleness time.Duration
 	CephFSRootPath       string
 	CephFSRootMountPath  string
+	CephFSMountless      bool
+}
+
+// TencentCloudConfig is a set of configurations used to access the Tencent Cloud APIs for CBS volumes.
+type TencentCloudConfig struct {
+	Region      string
+	CBSEndpoint string
+	CVMEndpoint string
+	SecretID    string
+	SecretKey   string
+	CacheTTL    time.Duration
+}
+
+// complete fills the secrets not given by flags from the environment.
+func (c *TencentCloudConfig) complete() {
+	if len(c.SecretID) == 0 {
+		c.SecretID = os.Getenv("TENCENTCLOUD_SECRET_ID")
+	}
+	if len(c.SecretKey) == 0 {
+		c.SecretKey = os.Getenv("TENCENTCLOUD_SECRET_KEY")
+	}
+}
+
+// NFSConfig is a set of configurations used to manage NFS volumes.
+type NFSConfig struct {
+	RootMountPath string
+	MountOptions  string
 }
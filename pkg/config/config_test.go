@@ -0,0 +1,48 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestTencentCloudSecretsNotDefaults(t *testing.T) {
+	os.Setenv("TENCENTCLOUD_SECRET_ID", "env-id")
+	os.Setenv("TENCENTCLOUD_SECRET_KEY", "env-key")
+	defer os.Unsetenv("TENCENTCLOUD_SECRET_ID")
+	defer os.Unsetenv("TENCENTCLOUD_SECRET_KEY")
+
+	cfg := &Config{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.VolumeConfig.addFlagsTo(fs)
+	for _, name := range []string{"tencentcloud-secret-id", "tencentcloud-secret-key"} {
+		if value := fs.Lookup(name).DefValue; len(value) > 0 {
+			t.Errorf("Default of %s is printed: %s", name, value)
+		}
+	}
+
+	if err := fs.Parse([]string{"--tencentcloud-secret-id=flag-id"}); err != nil {
+		t.Fatalf("Parse flags failed: %v", err)
+	}
+	cfg.Complete()
+	if cfg.TencentCloudConfig.SecretID != "flag-id" || cfg.TencentCloudConfig.SecretKey != "env-key" {
+		t.Errorf("Unexpected secrets: %s, %s", cfg.TencentCloudConfig.SecretID, cfg.TencentCloudConfig.SecretKey)
+	}
+}
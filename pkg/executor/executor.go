@@ -0,0 +1,88 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// Executor runs commands.
+type Executor interface {
+	// Exec runs a command and returns its stdout. The command is killed if it
+	// doesn't finish in timeout or ctx is done.
+	Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error)
+}
+
+// New creates an Executor running commands on the host.
+func New() Executor {
+	return &hostExecutor{}
+}
+
+// hostExecutor runs commands as child processes. Each command is started in
+// its own process group, so that the processes it forked are killed together.
+type hostExecutor struct{}
+
+// Exec runs a command and returns its stdout.
+func (e *hostExecutor) Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("execute command(%s %v) canceled: %v", name, args, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	command := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := command.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-exited:
+		case <-ctx.Done():
+			if err := syscall.Kill(-command.Process.Pid, syscall.SIGKILL); err != nil {
+				klog.Errorf("Kill process failed: %s %v, %v", name, args, err)
+			} else {
+				klog.Errorf("Execute command %s %v killed(%v): %d", name, args, ctx.Err(), command.Process.Pid)
+			}
+		}
+	}()
+
+	if err := command.Wait(); err != nil {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			return nil, fmt.Errorf("execute command(%s %v) timeout(%v) with error(%v)", name, args, timeout, err)
+		case context.Canceled:
+			return nil, fmt.Errorf("execute command(%s %v) canceled with error(%v)", name, args, err)
+		}
+		return nil, fmt.Errorf("execute cmd %s %v failed output: %s, error: %v", name, args, stderr.String(), err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,168 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// NewRecorder creates an Executor running commands by executor and saving their outputs
+// to dir, so that they can be replayed by an Executor created by NewReplayer. The
+// ignoredFlags and their values, such as the credentials, are not part of the records.
+func NewRecorder(executor Executor, dir string, ignoredFlags ...string) (Executor, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create record dir %s failed: %v", dir, err)
+	}
+	return &recorder{executor: executor, dir: dir, ignoredFlags: ignoredFlags}, nil
+}
+
+// recorder saves the output of each command to a file under dir.
+type recorder struct {
+	executor     Executor
+	dir          string
+	ignoredFlags []string
+}
+
+// Exec runs a command by the underlying executor and records its output.
+func (r *recorder) Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	output, err := r.executor.Exec(ctx, timeout, name, args...)
+	if ctx.Err() != nil {
+		// The output of an interrupted command says nothing about the storage.
+		return output, err
+	}
+	rec := &record{Command: recordCommand(name, args, r.ignoredFlags), Output: string(output)}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if saveErr := saveRecord(recordPath(r.dir, rec.Command), rec); saveErr != nil {
+		klog.Errorf("Record command %s %v failed: %v", name, args, saveErr)
+	}
+	return output, err
+}
+
+// NewReplayer creates an Executor serving the outputs recorded in dir instead of
+// running commands. The ignoredFlags must be the same as the ones recorded with.
+func NewReplayer(dir string, ignoredFlags ...string) Executor {
+	return &replayer{dir: dir, ignoredFlags: ignoredFlags}
+}
+
+// replayer serves the outputs recorded by a recorder.
+type replayer struct {
+	dir          string
+	ignoredFlags []string
+}
+
+// Exec returns the recorded output of a command, and the recorded error if the command failed.
+func (r *replayer) Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("execute command(%s %v) canceled: %v", name, args, err)
+	}
+	command := recordCommand(name, args, r.ignoredFlags)
+	path := recordPath(r.dir, command)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no record of command %v in %s", command, r.dir)
+		}
+		return nil, fmt.Errorf("read record %s failed: %v", path, err)
+	}
+	rec := &record{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("unmarshal record %s failed: %v", path, err)
+	}
+	if len(rec.Error) > 0 {
+		return nil, errors.New(rec.Error)
+	}
+	return []byte(rec.Output), nil
+}
+
+// record is the output of a command saved in a file.
+type record struct {
+	// Command is the name and the arguments of the command, without the ignored flags.
+	Command []string `json:"command"`
+	Output  string   `json:"output"`
+	// Error is the error message if the command failed, it contains the stderr of the command.
+	Error string `json:"error,omitempty"`
+}
+
+// recordCommand returns the name and the arguments of a command without the ignored flags and their values.
+func recordCommand(name string, args []string, ignoredFlags []string) []string {
+	command := []string{name}
+	for i := 0; i < len(args); i++ {
+		ignored := false
+		for _, flag := range ignoredFlags {
+			if args[i] == flag {
+				// Skip the value too.
+				ignored = true
+				i++
+				break
+			}
+			if strings.HasPrefix(args[i], flag+"=") {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			command = append(command, args[i])
+		}
+	}
+	return command
+}
+
+// recordPath returns the path of the record of a command, it is named after the command
+// name and the hash of the command so that the same command always hits the same file.
+func recordPath(dir string, command []string) string {
+	hash := sha256.Sum256([]byte(strings.Join(command, "\x00")))
+	return filepath.Join(dir, filepath.Base(command[0])+"-"+hex.EncodeToString(hash[:8])+".json")
+}
+
+// saveRecord writes a record to path atomically.
+func saveRecord(path string, rec *record) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal record of %v failed: %v", rec.Command, err)
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), ".record-")
+	if err != nil {
+		return fmt.Errorf("create record of %v failed: %v", rec.Command, err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("write record %s failed: %v", file.Name(), err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close record %s failed: %v", file.Name(), err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("save record %s failed: %v", path, err)
+	}
+	return nil
+}
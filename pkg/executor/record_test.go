@@ -0,0 +1,89 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeExecutor returns the outputs and errors of commands keyed by their args.
+type fakeExecutor struct {
+	outputs map[string]string
+	errors  map[string]error
+}
+
+func (e *fakeExecutor) Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	key := name + " " + args[0]
+	return []byte(e.outputs[key]), e.errors[key]
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "records")
+	if err != nil {
+		t.Fatalf("Create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fake := &fakeExecutor{
+		outputs: map[string]string{"rbd status": `{"watchers":[]}`},
+		errors:  map[string]error{"rbd info": errors.New("No such file or directory")},
+	}
+	recorder, err := NewRecorder(fake, dir, "--keyring", "--id")
+	if err != nil {
+		t.Fatalf("Create recorder failed: %v", err)
+	}
+	ctx := context.Background()
+	recorder.Exec(ctx, time.Minute, "rbd", "status", "img", "--keyring", "/tmp/k1", "--id=admin")
+	recorder.Exec(ctx, time.Minute, "rbd", "info", "img", "--keyring", "/tmp/k1")
+
+	// The ignored flags may differ when replaying.
+	replayer := NewReplayer(dir, "--keyring", "--id")
+	output, err := replayer.Exec(ctx, time.Minute, "rbd", "status", "img", "--keyring", "/tmp/k2", "--id=user")
+	if err != nil || string(output) != `{"watchers":[]}` {
+		t.Errorf("Unexpected replay of rbd status: %s, %v", output, err)
+	}
+	if _, err := replayer.Exec(ctx, time.Minute, "rbd", "info", "img"); err == nil ||
+		err.Error() != "No such file or directory" {
+		t.Errorf("Expected the recorded error, got %v", err)
+	}
+	if _, err := replayer.Exec(ctx, time.Minute, "rbd", "status", "img-2"); err == nil {
+		t.Errorf("Expected error of command not recorded")
+	}
+}
+
+func TestRecordCommand(t *testing.T) {
+	for _, c := range []struct {
+		args    []string
+		command []string
+	}{
+		{[]string{"status", "img"}, []string{"ceph", "status", "img"}},
+		{[]string{"status", "-c", "/etc/ceph/ceph.conf", "-m", "10.0.0.1:6789"}, []string{"ceph", "status"}},
+		{[]string{"status", "--id=admin", "--format", "json"}, []string{"ceph", "status", "--format", "json"}},
+	} {
+		command := recordCommand("ceph", c.args, []string{"-c", "-m", "--id"})
+		if !reflect.DeepEqual(command, c.command) {
+			t.Errorf("Args %v: expected %v, got %v", c.args, c.command, command)
+		}
+	}
+}
@@ -24,11 +24,13 @@ import (
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
 	storagev1 "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/typed/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/typed/storage/v2"
 )
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	StorageV1() storagev1.StorageV1Interface
+	StorageV2() storagev2.StorageV2Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -36,6 +38,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	storageV1 *storagev1.StorageV1Client
+	storageV2 *storagev2.StorageV2Client
 }
 
 // StorageV1 retrieves the StorageV1Client
@@ -43,6 +46,11 @@ func (c *Clientset) StorageV1() storagev1.StorageV1Interface {
 	return c.storageV1
 }
 
+// StorageV2 retrieves the StorageV2Client
+func (c *Clientset) StorageV2() storagev2.StorageV2Interface {
+	return c.storageV2
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -63,6 +71,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.storageV2, err = storagev2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -76,6 +88,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.storageV1 = storagev1.NewForConfigOrDie(c)
+	cs.storageV2 = storagev2.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -85,6 +98,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.storageV1 = storagev1.New(c)
+	cs.storageV2 = storagev2.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
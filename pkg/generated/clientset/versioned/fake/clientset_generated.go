@@ -28,6 +28,8 @@ import (
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
 	storagev1 "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/typed/storage/v1"
 	fakestoragev1 "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/typed/storage/v1/fake"
+	storagev2 "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/typed/storage/v2"
+	fakestoragev2 "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/typed/storage/v2/fake"
 )
 
 // NewSimpleClientset returns a clientset that will respond with the provided objects.
@@ -81,3 +83,8 @@ var _ clientset.Interface = &Clientset{}
 func (c *Clientset) StorageV1() storagev1.StorageV1Interface {
 	return &fakestoragev1.FakeStorageV1{Fake: &c.Fake}
 }
+
+// StorageV2 retrieves the StorageV2Client
+func (c *Clientset) StorageV2() storagev2.StorageV2Interface {
+	return &fakestoragev2.FakeStorageV2{Fake: &c.Fake}
+}
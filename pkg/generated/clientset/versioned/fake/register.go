@@ -26,6 +26,7 @@ import (
 	serializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	storagev1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 )
 
 var scheme = runtime.NewScheme()
@@ -33,6 +34,7 @@ var codecs = serializer.NewCodecFactory(scheme)
 var parameterCodec = runtime.NewParameterCodec(scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	storagev1.AddToScheme,
+	storagev2.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
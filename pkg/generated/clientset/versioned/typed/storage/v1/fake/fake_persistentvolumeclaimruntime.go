@@ -35,9 +35,9 @@ type FakePersistentVolumeClaimRuntimes struct {
 	ns   string
 }
 
-var persistentvolumeclaimruntimesResource = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "persistentvolumeclaimruntimes"}
+var persistentvolumeclaimruntimesResource = schema.GroupVersionResource{Group: "storage.tkestack.io", Version: "v1", Resource: "persistentvolumeclaimruntimes"}
 
-var persistentvolumeclaimruntimesKind = schema.GroupVersionKind{Group: "storage.k8s.io", Version: "v1", Kind: "PersistentVolumeClaimRuntime"}
+var persistentvolumeclaimruntimesKind = schema.GroupVersionKind{Group: "storage.tkestack.io", Version: "v1", Kind: "PersistentVolumeClaimRuntime"}
 
 // Get takes name of the persistentVolumeClaimRuntime, and returns the corresponding persistentVolumeClaimRuntime object, and an error if there is any.
 func (c *FakePersistentVolumeClaimRuntimes) Get(name string, options v1.GetOptions) (result *storagev1.PersistentVolumeClaimRuntime, err error) {
@@ -101,6 +101,18 @@ func (c *FakePersistentVolumeClaimRuntimes) Update(persistentVolumeClaimRuntime
 	return obj.(*storagev1.PersistentVolumeClaimRuntime), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakePersistentVolumeClaimRuntimes) UpdateStatus(persistentVolumeClaimRuntime *storagev1.PersistentVolumeClaimRuntime) (*storagev1.PersistentVolumeClaimRuntime, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(persistentvolumeclaimruntimesResource, "status", c.ns, persistentVolumeClaimRuntime), &storagev1.PersistentVolumeClaimRuntime{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storagev1.PersistentVolumeClaimRuntime), err
+}
+
 // Delete takes name of the persistentVolumeClaimRuntime and deletes it. Returns an error if one occurs.
 func (c *FakePersistentVolumeClaimRuntimes) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
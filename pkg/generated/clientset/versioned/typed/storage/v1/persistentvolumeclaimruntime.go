@@ -40,6 +40,7 @@ type PersistentVolumeClaimRuntimesGetter interface {
 type PersistentVolumeClaimRuntimeInterface interface {
 	Create(*v1.PersistentVolumeClaimRuntime) (*v1.PersistentVolumeClaimRuntime, error)
 	Update(*v1.PersistentVolumeClaimRuntime) (*v1.PersistentVolumeClaimRuntime, error)
+	UpdateStatus(*v1.PersistentVolumeClaimRuntime) (*v1.PersistentVolumeClaimRuntime, error)
 	Delete(name string, options *metav1.DeleteOptions) error
 	DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error
 	Get(name string, options metav1.GetOptions) (*v1.PersistentVolumeClaimRuntime, error)
@@ -133,6 +134,22 @@ func (c *persistentVolumeClaimRuntimes) Update(persistentVolumeClaimRuntime *v1.
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *persistentVolumeClaimRuntimes) UpdateStatus(persistentVolumeClaimRuntime *v1.PersistentVolumeClaimRuntime) (result *v1.PersistentVolumeClaimRuntime, err error) {
+	result = &v1.PersistentVolumeClaimRuntime{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("persistentvolumeclaimruntimes").
+		Name(persistentVolumeClaimRuntime.Name).
+		SubResource("status").
+		Body(persistentVolumeClaimRuntime).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the persistentVolumeClaimRuntime and deletes it. Returns an error if one occurs.
 func (c *persistentVolumeClaimRuntimes) Delete(name string, options *metav1.DeleteOptions) error {
 	return c.client.Delete().
@@ -30,7 +30,7 @@ type StorageV1Interface interface {
 	PersistentVolumeClaimRuntimesGetter
 }
 
-// StorageV1Client is used to interact with features provided by the storage.k8s.io group.
+// StorageV1Client is used to interact with features provided by the storage.tkestack.io group.
 type StorageV1Client struct {
 	restClient rest.Interface
 }
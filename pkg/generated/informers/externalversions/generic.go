@@ -25,6 +25,7 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 	v1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	v2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 )
 
 // GenericInformer is type of SharedIndexInformer which will locate and delegate to other
@@ -53,10 +54,18 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=storage.k8s.io, Version=v1
+	// Group=storage.tkestack.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("persistentvolumeclaimruntimes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Storage().V1().PersistentVolumeClaimRuntimes().Informer()}, nil
 
+		// Group=storage.tkestack.io, Version=v2
+	case v2.SchemeGroupVersion.WithResource("namespacestorageruntimes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Storage().V2().NamespaceStorageRuntimes().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("persistentvolumeclaimruntimes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Storage().V2().PersistentVolumeClaimRuntimes().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("storageclassruntimes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Storage().V2().StorageClassRuntimes().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
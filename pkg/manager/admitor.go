@@ -23,7 +23,7 @@ import (
 	"io/ioutil"
 	"net/http"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	"tkestack.io/volume-decorator/pkg/util"
 	"tkestack.io/volume-decorator/pkg/volume"
 	"tkestack.io/volume-decorator/pkg/workload"
@@ -51,7 +51,14 @@ type admitor struct {
 // handle handles an admission request.
 func (a *admitor) handle(w http.ResponseWriter, req *http.Request) {
 	klog.V(5).Info("Receive workload request")
+	serveAdmission(w, req, a.handleWorkload)
+}
 
+// serveAdmission decodes an AdmissionReview from req, and writes the response generated by handler.
+func serveAdmission(
+	w http.ResponseWriter,
+	req *http.Request,
+	handler func(*admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionReview) {
 	if req.Body == nil {
 		klog.Error("Receive an invalid request, body is empty")
 		response(w, http.StatusBadRequest, "request body required")
@@ -73,10 +80,10 @@ func (a *admitor) handle(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	klog.V(5).Infof("Receive workload %s request: %+v/%s/%s",
+	klog.V(5).Infof("Receive %s request: %+v/%s/%s",
 		request.Request.Operation, request.Request.Resource, request.Request.Namespace, request.Request.Name)
 
-	respBytes, err := json.Marshal(a.handleWorkload(request))
+	respBytes, err := json.Marshal(handler(request))
 	if err != nil {
 		response(w, http.StatusInternalServerError, fmt.Sprintf("marshal response failed: %v", err))
 		return
@@ -109,12 +116,12 @@ func (a *admitor) handleWorkload(request *admissionv1beta1.AdmissionReview) *adm
 
 	now := metav1.Now()
 	for _, vol := range usedVolumes {
-		err := a.volumeManager.Attach(&storagev1alpha1.Workload{
+		err := a.volumeManager.Attach(&storagev2.Workload{
 			ObjectReference: w.ObjectReference,
 			ReadOnly:        vol.ReadOnly,
 			Replicas:        w.Replicas,
 			Timestamp:       &now,
-		}, request.Request.Namespace, vol.ClaimName)
+		}, w.PodSpecs, request.Request.Namespace, vol.ClaimName)
 		if err != nil {
 			resp.Response.Result = statusFromError(err)
 			return resp
@@ -0,0 +1,216 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"tkestack.io/volume-decorator/pkg/apis/storage"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/util"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// claimCreatorAnnotation is the annotation of PVCs recording the creator, its value is a json encoded UserInfo.
+	claimCreatorAnnotation = storage.GroupName + "/creator"
+	// claimCreatorSignatureAnnotation is the signature of the creator stamped by the claim admission. The
+	// annotations are writable by users, and are not intercepted if the webhook is unavailable, so a
+	// creator without a valid signature is never trusted.
+	claimCreatorSignatureAnnotation = storage.GroupName + "/creator-signature"
+)
+
+// newClaimAdmitor creates a claimAdmitor.
+func newClaimAdmitor(signer *claimSigner) *claimAdmitor {
+	return &claimAdmitor{signer: signer}
+}
+
+// claimAdmitor records the creators of PVCs.
+type claimAdmitor struct {
+	signer *claimSigner
+}
+
+// handle handles a PVC admission request.
+func (a *claimAdmitor) handle(w http.ResponseWriter, req *http.Request) {
+	klog.V(5).Info("Receive claim request")
+	serveAdmission(w, req, a.handleClaim)
+}
+
+// handleClaim records the requesting user as the creator of a PVC when it is created,
+// and rejects the updates changing the creator.
+func (a *claimAdmitor) handleClaim(request *admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionReview {
+	resp := &admissionv1beta1.AdmissionReview{
+		Response: &admissionv1beta1.AdmissionResponse{UID: request.Request.UID},
+	}
+	markResponseAsSuccess(resp)
+	switch request.Request.Operation {
+	case admissionv1beta1.Create:
+		a.stampCreator(request.Request, resp.Response)
+	case admissionv1beta1.Update:
+		a.checkCreator(request.Request, resp.Response)
+	}
+	return resp
+}
+
+// stampCreator patches the signed creator to a PVC being created.
+func (a *claimAdmitor) stampCreator(request *admissionv1beta1.AdmissionRequest, resp *admissionv1beta1.AdmissionResponse) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if _, _, err := util.Codecs.UniversalDeserializer().Decode(request.Object.Raw, nil, pvc); err != nil {
+		// Never block the PVC, just leave its creator unknown.
+		klog.Errorf("Decode PVC %s/%s failed: %v", request.Namespace, request.Name, err)
+		return
+	}
+
+	userInfo := request.UserInfo
+	patch, err := a.creatorPatch(pvc, &storagev2.UserInfo{
+		Username: userInfo.Username,
+		UID:      userInfo.UID,
+		Groups:   userInfo.Groups,
+	})
+	if err != nil {
+		klog.Errorf("Generate creator patch of PVC %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	patchType := admissionv1beta1.PatchTypeJSONPatch
+	resp.Patch = patch
+	resp.PatchType = &patchType
+}
+
+// checkCreator rejects an update of a PVC if the creator annotations are changed.
+func (a *claimAdmitor) checkCreator(request *admissionv1beta1.AdmissionRequest, resp *admissionv1beta1.AdmissionResponse) {
+	pvc, oldPVC := &corev1.PersistentVolumeClaim{}, &corev1.PersistentVolumeClaim{}
+	decoder := util.Codecs.UniversalDeserializer()
+	if _, _, err := decoder.Decode(request.Object.Raw, nil, pvc); err != nil {
+		klog.Errorf("Decode PVC %s/%s failed: %v", request.Namespace, request.Name, err)
+		return
+	}
+	if _, _, err := decoder.Decode(request.OldObject.Raw, nil, oldPVC); err != nil {
+		klog.Errorf("Decode old PVC %s/%s failed: %v", request.Namespace, request.Name, err)
+		return
+	}
+	for _, key := range []string{claimCreatorAnnotation, claimCreatorSignatureAnnotation} {
+		if pvc.Annotations[key] == oldPVC.Annotations[key] {
+			continue
+		}
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: fmt.Sprintf("annotation %s is set by the claim admission and can't be changed", key),
+			Code:    http.StatusForbidden,
+		}
+		return
+	}
+}
+
+// creatorPatch generates a json patch to set the creator annotations of a PVC.
+func (a *claimAdmitor) creatorPatch(pvc *corev1.PersistentVolumeClaim, userInfo *storagev2.UserInfo) ([]byte, error) {
+	creator, err := json.Marshal(userInfo)
+	if err != nil {
+		return nil, fmt.Errorf("marshal creator failed: %v", err)
+	}
+	annotations := map[string]string{
+		claimCreatorAnnotation: string(creator),
+		// The name is empty if it is generated.
+		claimCreatorSignatureAnnotation: a.signer.sign(pvc.Namespace, pvc.Name, creator),
+	}
+
+	var patch []map[string]interface{}
+	if pvc.Annotations == nil {
+		patch = append(patch, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations",
+			"value": annotations,
+		})
+		return json.Marshal(patch)
+	}
+	for _, key := range []string{claimCreatorAnnotation, claimCreatorSignatureAnnotation} {
+		// The annotation given by the user is overwritten, "add" replaces an existing member.
+		patch = append(patch, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations/" + escapeJSONPointer(key),
+			"value": annotations[key],
+		})
+	}
+	return json.Marshal(patch)
+}
+
+// escapeJSONPointer escapes a token of json pointer.
+func escapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// newClaimSigner creates a claimSigner whose key is derived from the private key of the webhook
+// in keyFile, which is shared by all the replicas serving the webhook.
+func newClaimSigner(keyFile string) (*claimSigner, error) {
+	data, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read webhook key %s failed: %v", keyFile, err)
+	}
+	key := sha256.Sum256(append([]byte(claimCreatorSignatureAnnotation+"\x00"), data...))
+	return &claimSigner{key: key[:]}, nil
+}
+
+// claimSigner signs the creators recorded by the claim admission. A nil claimSigner trusts no creator.
+type claimSigner struct {
+	key []byte
+}
+
+// sign returns the signature of the creator of a PVC.
+func (s *claimSigner) sign(namespace, name string, creator []byte) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(namespace + "/" + name + "\x00"))
+	mac.Write(creator)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// creator returns the creator recorded in the annotations of a PVC, or nil if it is not recorded or
+// not signed by the claim admission. The signature of a PVC with a generated name is made before
+// the name is known, it is checked without the name.
+func (s *claimSigner) creator(pvc *corev1.PersistentVolumeClaim) *storagev2.UserInfo {
+	value, exist := pvc.Annotations[claimCreatorAnnotation]
+	if !exist || s == nil {
+		return nil
+	}
+	signature := []byte(pvc.Annotations[claimCreatorSignatureAnnotation])
+	signed := hmac.Equal(signature, []byte(s.sign(pvc.Namespace, pvc.Name, []byte(value))))
+	if !signed && len(pvc.GenerateName) > 0 {
+		signed = hmac.Equal(signature, []byte(s.sign(pvc.Namespace, "", []byte(value))))
+	}
+	if !signed {
+		klog.V(4).Infof("Creator of PVC %s/%s is not signed by the claim admission, ignore it", pvc.Namespace, pvc.Name)
+		return nil
+	}
+	creator := &storagev2.UserInfo{}
+	if err := json.Unmarshal([]byte(value), creator); err != nil {
+		klog.Errorf("Unmarshal creator of PVC %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
+		return nil
+	}
+	return creator
+}
@@ -0,0 +1,199 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newClaimReview creates an AdmissionReview of a PVC requested by alice.
+func newClaimReview(
+	t *testing.T,
+	operation admissionv1beta1.Operation,
+	pvc, oldPVC *corev1.PersistentVolumeClaim) *admissionv1beta1.AdmissionReview {
+	request := &admissionv1beta1.AdmissionRequest{
+		Namespace: pvc.Namespace,
+		Name:      pvc.Name,
+		Operation: operation,
+		UserInfo:  authenticationv1.UserInfo{Username: "alice", UID: "1", Groups: []string{"dev"}},
+	}
+	for obj, raw := range map[*corev1.PersistentVolumeClaim]*runtime.RawExtension{
+		pvc:    &request.Object,
+		oldPVC: &request.OldObject,
+	} {
+		if obj == nil {
+			continue
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("Marshal PVC failed: %v", err)
+		}
+		raw.Raw = data
+	}
+	return &admissionv1beta1.AdmissionReview{Request: request}
+}
+
+// applyAnnotationPatch applies the "add" operations of a json patch generated by creatorPatch.
+func applyAnnotationPatch(t *testing.T, pvc *corev1.PersistentVolumeClaim, patch []byte) {
+	var operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		t.Fatalf("Unmarshal patch failed: %v", err)
+	}
+	for _, operation := range operations {
+		if operation.Op != "add" {
+			t.Fatalf("Unexpected operation %s", operation.Op)
+		}
+		if operation.Path == "/metadata/annotations" {
+			if err := json.Unmarshal(operation.Value, &pvc.Annotations); err != nil {
+				t.Fatalf("Unmarshal annotations failed: %v", err)
+			}
+			continue
+		}
+		key := strings.TrimPrefix(operation.Path, "/metadata/annotations/")
+		key = strings.Replace(strings.Replace(key, "~1", "/", -1), "~0", "~", -1)
+		var value string
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			t.Fatalf("Unmarshal annotation failed: %v", err)
+		}
+		pvc.Annotations[key] = value
+	}
+}
+
+func TestClaimCreator(t *testing.T) {
+	signer := &claimSigner{key: []byte("key")}
+	admitor := newClaimAdmitor(signer)
+	expected := &storagev2.UserInfo{Username: "alice", UID: "1", Groups: []string{"dev"}}
+
+	for _, c := range []struct {
+		name         string
+		pvc          *corev1.PersistentVolumeClaim
+		generateName string
+	}{
+		{
+			name: "without annotations",
+			pvc:  &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data"}},
+		},
+		{
+			name: "creator given by the user",
+			pvc: &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data",
+				Annotations: map[string]string{claimCreatorAnnotation: `{"username":"bob"}`}}},
+		},
+		{
+			name: "generated name",
+			pvc: &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", GenerateName: "data-"}},
+			generateName: "data-x7k2p",
+		},
+	} {
+		resp := admitor.handleClaim(newClaimReview(t, admissionv1beta1.Create, c.pvc, nil))
+		if !resp.Response.Allowed || resp.Response.Patch == nil {
+			t.Errorf("%s: expected the creator patched, got %+v", c.name, resp.Response)
+			continue
+		}
+		pvc := c.pvc.DeepCopy()
+		applyAnnotationPatch(t, pvc, resp.Response.Patch)
+		if len(c.generateName) > 0 {
+			pvc.Name = c.generateName
+		}
+		if creator := signer.creator(pvc); !reflect.DeepEqual(creator, expected) {
+			t.Errorf("%s: expected creator %+v, got %+v", c.name, expected, creator)
+		}
+	}
+}
+
+func TestClaimCreatorNotSigned(t *testing.T) {
+	signer := &claimSigner{key: []byte("key")}
+	creator := `{"username":"alice"}`
+	signed := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data",
+		Annotations: map[string]string{
+			claimCreatorAnnotation:          creator,
+			claimCreatorSignatureAnnotation: signer.sign("ns", "data", []byte(creator)),
+		}}}
+	if signer.creator(signed) == nil {
+		t.Fatalf("Expected the signed creator trusted")
+	}
+
+	// A PVC created while the webhook is unavailable keeps the annotations given by the user.
+	forged := signed.DeepCopy()
+	forged.Annotations[claimCreatorAnnotation] = `{"username":"bob"}`
+	// The signature copied from another PVC.
+	copied := signed.DeepCopy()
+	copied.Name = "other"
+	withoutSignature := signed.DeepCopy()
+	delete(withoutSignature.Annotations, claimCreatorSignatureAnnotation)
+	for name, pvc := range map[string]*corev1.PersistentVolumeClaim{
+		"forged":            forged,
+		"copied":            copied,
+		"without signature": withoutSignature,
+	} {
+		if creator := signer.creator(pvc); creator != nil {
+			t.Errorf("%s: expected creator ignored, got %+v", name, creator)
+		}
+	}
+	if creator := (*claimSigner)(nil).creator(signed); creator != nil {
+		t.Errorf("Expected no creator trusted without the claim admission, got %+v", creator)
+	}
+}
+
+func TestClaimCreatorUpdate(t *testing.T) {
+	admitor := newClaimAdmitor(&claimSigner{key: []byte("key")})
+	oldPVC := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data",
+		Annotations: map[string]string{
+			claimCreatorAnnotation:          `{"username":"alice"}`,
+			claimCreatorSignatureAnnotation: "signature",
+		}}}
+
+	labeled := oldPVC.DeepCopy()
+	labeled.Labels = map[string]string{"app": "db"}
+	changed := oldPVC.DeepCopy()
+	changed.Annotations[claimCreatorAnnotation] = `{"username":"bob"}`
+	removed := oldPVC.DeepCopy()
+	delete(removed.Annotations, claimCreatorSignatureAnnotation)
+	for _, c := range []struct {
+		name    string
+		pvc     *corev1.PersistentVolumeClaim
+		allowed bool
+	}{
+		{name: "creator unchanged", pvc: labeled, allowed: true},
+		{name: "creator changed", pvc: changed},
+		{name: "signature removed", pvc: removed},
+	} {
+		resp := admitor.handleClaim(newClaimReview(t, admissionv1beta1.Update, c.pvc, oldPVC))
+		if resp.Response.Allowed != c.allowed {
+			t.Errorf("%s: expected allowed %t, got %+v", c.name, c.allowed, resp.Response.Result)
+		}
+		if resp.Response.Patch != nil {
+			t.Errorf("%s: unexpected patch %s", c.name, resp.Response.Patch)
+		}
+	}
+}
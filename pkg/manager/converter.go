@@ -0,0 +1,136 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+)
+
+// pvcrKind is the kind of PersistentVolumeClaimRuntime.
+const pvcrKind = "PersistentVolumeClaimRuntime"
+
+// convert handles a conversion request of PersistentVolumeClaimRuntime crd.
+func convert(w http.ResponseWriter, req *http.Request) {
+	klog.V(5).Info("Receive conversion request")
+
+	if req.Body == nil {
+		klog.Error("Receive an invalid conversion request, body is empty")
+		response(w, http.StatusBadRequest, "request body required")
+		return
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		klog.Errorf("Read conversion request body failed: %v", err)
+		response(w, http.StatusInternalServerError, fmt.Sprintf("read request body failed: %v", err))
+		return
+	}
+
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.Unmarshal(data, review); err != nil || review.Request == nil {
+		klog.Errorf("Parse conversion request body failed: %s, %v", string(data), err)
+		response(w, http.StatusBadRequest, fmt.Sprintf("parse request failed: %v", err))
+		return
+	}
+
+	klog.V(5).Infof("Receive conversion request %s: %d objects to %s",
+		review.Request.UID, len(review.Request.Objects), review.Request.DesiredAPIVersion)
+
+	review.Response = convertObjects(review.Request)
+	review.Request = nil
+	respBytes, err := json.Marshal(review)
+	if err != nil {
+		response(w, http.StatusInternalServerError, fmt.Sprintf("marshal response failed: %v", err))
+		return
+	}
+	if _, err := w.Write(respBytes); err != nil {
+		klog.Errorf("Send conversion response failed: %v", err)
+	}
+}
+
+// convertObjects converts all objects of a ConversionRequest to the desired version.
+func convertObjects(request *apiextensionsv1beta1.ConversionRequest) *apiextensionsv1beta1.ConversionResponse {
+	resp := &apiextensionsv1beta1.ConversionResponse{UID: request.UID}
+	for _, obj := range request.Objects {
+		converted, err := convertObject(obj.Raw, request.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("Convert object to %s failed: %v", request.DesiredAPIVersion, err)
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// convertObject converts a PersistentVolumeClaimRuntime to the desired version through the hub version v2.
+func convertObject(data []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := json.Unmarshal(data, typeMeta); err != nil {
+		return nil, fmt.Errorf("parse object failed: %v", err)
+	}
+	if typeMeta.Kind != pvcrKind {
+		return nil, fmt.Errorf("unsupported kind: %s", typeMeta.Kind)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return data, nil
+	}
+
+	hub := &storagev2.PersistentVolumeClaimRuntime{}
+	switch typeMeta.APIVersion {
+	case storagev1alpha1.SchemeGroupVersion.String():
+		pvcr := &storagev1alpha1.PersistentVolumeClaimRuntime{}
+		if err := json.Unmarshal(data, pvcr); err != nil {
+			return nil, fmt.Errorf("parse %s object failed: %v", typeMeta.APIVersion, err)
+		}
+		pvcr.ConvertTo(hub)
+	case storagev2.SchemeGroupVersion.String():
+		if err := json.Unmarshal(data, hub); err != nil {
+			return nil, fmt.Errorf("parse %s object failed: %v", typeMeta.APIVersion, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported version: %s", typeMeta.APIVersion)
+	}
+
+	switch desiredAPIVersion {
+	case storagev1alpha1.SchemeGroupVersion.String():
+		pvcr := &storagev1alpha1.PersistentVolumeClaimRuntime{}
+		pvcr.ConvertFrom(hub)
+		return json.Marshal(pvcr)
+	case storagev2.SchemeGroupVersion.String():
+		return json.Marshal(hub)
+	default:
+		return nil, fmt.Errorf("unsupported desired version: %s", desiredAPIVersion)
+	}
+}
@@ -18,100 +18,206 @@
 package manager
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
 
 	"tkestack.io/volume-decorator/pkg/apis/storage"
+	"tkestack.io/volume-decorator/pkg/config"
 
-	extensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/rest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/klog"
 )
 
-var schema = &extensionsv1beta1.JSONSchemaProps{
-	Properties: map[string]extensionsv1beta1.JSONSchemaProps{
-		"apiVersion": {Type: "string"},
-		"kind":       {Type: "string"},
-		"metadata":   {Type: "object"},
-		"spec": {
-			Type: "object",
-			Properties: map[string]extensionsv1beta1.JSONSchemaProps{
-				"statuses":     {Type: "array"},
-				"workloads":    {Type: "array"},
-				"usageBytes":   {Type: "int64"},
-				"mountedNodes": {Type: "array"},
-			},
-		},
-	},
-}
+// pvcrCRDName is the name of PersistentVolumeClaimRuntime crd.
+const pvcrCRDName = "persistentvolumeclaimruntimes." + storage.GroupName
 
-var csiCRD = &extensionsv1beta1.CustomResourceDefinition{
-	ObjectMeta: metav1.ObjectMeta{
-		Name: "persistentvolumeclaimruntimes." + storage.GroupName,
-	},
-	TypeMeta: metav1.TypeMeta{
-		Kind:       "CustomResourceDefinition",
-		APIVersion: "apiextensions.k8s.io/v1beta1",
-	},
-	Spec: extensionsv1beta1.CustomResourceDefinitionSpec{
-		Group: storage.GroupName,
-		Scope: extensionsv1beta1.ResourceScope("Namespaced"),
-		Names: extensionsv1beta1.CustomResourceDefinitionNames{
-			Plural:     "persistentvolumeclaimruntimes",
-			Singular:   "persistentvolumeclaimruntime",
-			Kind:       "PersistentVolumeClaimRuntime",
-			ListKind:   "PersistentVolumeClaimRuntimeList",
-			ShortNames: []string{"pvcr", "pvcrs"},
-		},
-		Versions: []extensionsv1beta1.CustomResourceDefinitionVersion{
-			{
-				Name:    "v1",
-				Served:  true,
-				Storage: true,
-			},
-		},
-		Validation: &extensionsv1beta1.CustomResourceValidation{
-			OpenAPIV3Schema: schema,
-		},
-	},
+// crdResource is the apiextensions.k8s.io/v1 CustomResourceDefinition resource.
+var crdResource = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
 }
 
-// syncCRD creates or updates the PVCR crd.
-func syncCRD(config *rest.Config) error {
-	client, err := apiextensionsclient.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("create apiextensions client failed: %v", err)
+// syncCRD creates or updates the CRDs generated from the API types.
+func syncCRD(crdClient dynamic.ResourceInterface) error {
+	for _, manifest := range crdManifests {
+		crd, err := decodeCRD(manifest)
+		if err != nil {
+			return err
+		}
+		if err := syncOneCRD(crdClient, crd); err != nil {
+			return err
+		}
 	}
-	crdClient := client.ApiextensionsV1beta1().CustomResourceDefinitions()
 
-	oldCRD, err := crdClient.Get(csiCRD.Name, metav1.GetOptions{})
+	return nil
+}
+
+// syncOneCRD creates the crd or updates its spec if it is changed.
+func syncOneCRD(crdClient dynamic.ResourceInterface, crd *unstructured.Unstructured) error {
+	oldCRD, err := crdClient.Get(crd.GetName(), metav1.GetOptions{})
 	if err != nil {
 		if !errors.IsNotFound(err) {
-			return fmt.Errorf("get crd failed: %v", err)
+			return fmt.Errorf("get crd %s failed: %v", crd.GetName(), err)
 		}
-		if _, createErr := crdClient.Create(csiCRD); createErr != nil {
-			return fmt.Errorf("create crd failed: %v", createErr)
+		if _, createErr := crdClient.Create(crd, metav1.CreateOptions{}); createErr != nil {
+			return fmt.Errorf("create crd %s failed: %v", crd.GetName(), createErr)
 		}
-		klog.Info("CRD created")
+		klog.Infof("CRD %s created", crd.GetName())
 		return nil
 	}
 
-	// Update the crd if needed.
-	if equality.Semantic.DeepEqual(oldCRD.Spec, csiCRD.Spec) {
-		klog.Info("CRD is already created, no need to update it")
+	// Update the crd if needed, the fields defaulted by apiserver are not in the generated spec.
+	if containsFields(oldCRD.Object["spec"], crd.Object["spec"]) {
+		klog.Infof("CRD %s is already created, no need to update it", crd.GetName())
 		return nil
 	}
 
-	klog.Info("Try to update crd")
+	klog.Infof("Try to update crd %s", crd.GetName())
 	newCRD := oldCRD.DeepCopy()
-	newCRD.Spec = csiCRD.Spec
-	_, updateErr := crdClient.Update(newCRD)
-	if updateErr == nil {
-		klog.Info("CRD updated")
+	newCRD.Object["spec"] = runtime.DeepCopyJSONValue(crd.Object["spec"])
+	// Conversion is managed by syncCRDConversion, keep it.
+	if conversion, found, _ := unstructured.NestedMap(oldCRD.Object, "spec", "conversion"); found {
+		if err := unstructured.SetNestedMap(newCRD.Object, conversion, "spec", "conversion"); err != nil {
+			return fmt.Errorf("keep conversion of crd %s failed: %v", crd.GetName(), err)
+		}
+	}
+	if _, err := crdClient.Update(newCRD, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update crd %s failed: %v", crd.GetName(), err)
+	}
+	klog.Infof("CRD %s updated", crd.GetName())
+
+	return nil
+}
+
+// containsFields returns true if all fields set in expected have the same values in exist,
+// the fields only in exist are ignored. Lists must have the same length.
+func containsFields(exist, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		exist, ok := exist.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range expected {
+			if !containsFields(exist[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		exist, ok := exist.([]interface{})
+		if !ok || len(exist) != len(expected) {
+			return false
+		}
+		for i := range expected {
+			if !containsFields(exist[i], expected[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return equality.Semantic.DeepEqual(exist, expected)
+	}
+}
+
+// decodeCRD decodes a generated yaml manifest into an unstructured CRD.
+func decodeCRD(manifest string) (*unstructured.Unstructured, error) {
+	reader := yaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no crd found in manifest")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read crd manifest failed: %v", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		data, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("convert crd manifest failed: %v", err)
+		}
+		crd := &unstructured.Unstructured{}
+		if err := crd.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("decode crd manifest failed: %v", err)
+		}
+		// Status is owned by the apiserver.
+		delete(crd.Object, "status")
+		return crd, nil
 	}
+}
+
+// syncCRDConversion points the conversion of PersistentVolumeClaimRuntime crd to our webhook,
+// nothing is done if the crd is not created yet.
+func syncCRDConversion(crdClient dynamic.ResourceInterface, webhookCfg *config.WebhookConfig) error {
+	caCert, err := ioutil.ReadFile(webhookCfg.CAFile)
+	if err != nil {
+		return fmt.Errorf("failed to read certificate authority from %s: %v", webhookCfg.CAFile, err)
+	}
+
+	clientConfig := map[string]interface{}{
+		"caBundle": base64.StdEncoding.EncodeToString(caCert),
+	}
+	if len(webhookCfg.URL) > 0 {
+		clientConfig["url"] = "https://" + strings.Trim(webhookCfg.URL, "/") + webhookCfg.ConversionPath
+	} else {
+		clientConfig["service"] = map[string]interface{}{
+			"name":      webhookCfg.ServiceName,
+			"namespace": webhookCfg.ServiceNamespace,
+			"path":      webhookCfg.ConversionPath,
+		}
+	}
+	conversion := map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig":             clientConfig,
+			"conversionReviewVersions": []interface{}{"v1beta1"},
+		},
+	}
+
+	crd, err := crdClient.Get(pvcrCRDName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Warningf("CRD %s not found, skip syncing its conversion", pvcrCRDName)
+			return nil
+		}
+		return fmt.Errorf("get crd %s failed: %v", pvcrCRDName, err)
+	}
+	exist, _, _ := unstructured.NestedMap(crd.Object, "spec", "conversion")
+	if conversionEqual(exist, conversion) {
+		return nil
+	}
+
+	newCRD := crd.DeepCopy()
+	if err := unstructured.SetNestedMap(newCRD.Object, conversion, "spec", "conversion"); err != nil {
+		return fmt.Errorf("set conversion of crd %s failed: %v", pvcrCRDName, err)
+	}
+	if _, err := crdClient.Update(newCRD, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update conversion of crd %s failed: %v", pvcrCRDName, err)
+	}
+	klog.Infof("Conversion webhook of crd %s updated", pvcrCRDName)
+
+	return nil
+}
 
-	return err
+// conversionEqual returns true if the conversion in cluster is the expected one. exist
+// must be a copy, and its service port is not compared since it is defaulted by apiserver.
+func conversionEqual(exist, expected map[string]interface{}) bool {
+	unstructured.RemoveNestedField(exist, "webhook", "clientConfig", "service", "port")
+	return equality.Semantic.DeepEqual(exist, expected)
 }
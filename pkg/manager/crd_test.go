@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import "testing"
+
+func TestContainsFields(t *testing.T) {
+	generated := map[string]interface{}{
+		"group": "storage.tkestack.io",
+		"versions": []interface{}{
+			map[string]interface{}{"name": "v1", "served": true},
+			map[string]interface{}{"name": "v2", "served": true, "storage": true},
+		},
+	}
+	for _, c := range []struct {
+		name     string
+		exist    interface{}
+		expected bool
+	}{
+		{
+			name: "defaulted by apiserver",
+			exist: map[string]interface{}{
+				"group":                 "storage.tkestack.io",
+				"preserveUnknownFields": false,
+				"conversion":            map[string]interface{}{"strategy": "None"},
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1", "served": true, "storage": false},
+					map[string]interface{}{"name": "v2", "served": true, "storage": true},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "changed value",
+			exist: map[string]interface{}{
+				"group": "storage.tkestack.io",
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1", "served": true},
+					map[string]interface{}{"name": "v2", "served": false, "storage": true},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "missing version",
+			exist: map[string]interface{}{
+				"group":    "storage.tkestack.io",
+				"versions": []interface{}{map[string]interface{}{"name": "v1", "served": true}},
+			},
+			expected: false,
+		},
+		{
+			name:     "missing spec",
+			exist:    nil,
+			expected: false,
+		},
+	} {
+		if got := containsFields(c.exist, generated); got != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.name, c.expected, got)
+		}
+	}
+}
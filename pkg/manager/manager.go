@@ -21,15 +21,19 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"tkestack.io/volume-decorator/pkg/config"
 	pvcrinformers "tkestack.io/volume-decorator/pkg/generated/informers/externalversions"
+	"tkestack.io/volume-decorator/pkg/nodes"
 	"tkestack.io/volume-decorator/pkg/tapps"
 	"tkestack.io/volume-decorator/pkg/util"
 	"tkestack.io/volume-decorator/pkg/volume"
 	"tkestack.io/volume-decorator/pkg/workload"
 
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -48,14 +52,30 @@ type manager struct {
 	informerFactory     informers.SharedInformerFactory
 	pvSynced            cache.InformerSynced
 	pvcSynced           cache.InformerSynced
+	nodeSynced          cache.InformerSynced
+	podSynced           cache.InformerSynced
+	nsSynced            cache.InformerSynced
+	scSynced            cache.InformerSynced
 	pvcrInformerFactory pvcrinformers.SharedInformerFactory
 	pvcrSynced          cache.InformerSynced
+	nsrSynced           cache.InformerSynced
+	scrSynced           cache.InformerSynced
+	crdClient           dynamic.ResourceInterface
+
+	// admissionReady is set to 1 when this replica is able to admit workloads.
+	admissionReady int32
 
 	admitor          *admitor
+	claimAdmitor     *claimAdmitor
 	pvcrManager      *pvcrManager
 	nodeCollector    *nodeCollector
 	usageCollector   *usageCollector
+	podCollector     *podCollector
+	nsCollector      *namespaceCollector
+	scCollector      *storageClassCollector
+	quotaReconciler  *quotaReconciler
 	workloadRecycler *workloadRecycler
+	migrator         *storageVersionMigrator
 	volumeManager    volume.Manager
 	workloadManager  workload.Manager
 
@@ -72,8 +92,13 @@ func New(cfg *config.Config) (Manager, error) {
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("create dynamic client failed: %v", err)
+	}
+	crdClient := dynamicClient.Resource(crdResource)
 	if cfg.CreateCRD {
-		if err := syncCRD(restCfg); err != nil {
+		if err := syncCRD(crdClient); err != nil {
 			return nil, err
 		}
 	}
@@ -91,32 +116,91 @@ func New(cfg *config.Config) (Manager, error) {
 	informerFactory := informers.NewSharedInformerFactory(k8sClient, k8sConfig.ResyncPeriod)
 	pvInformer := informerFactory.Core().V1().PersistentVolumes()
 	pvcInformer := informerFactory.Core().V1().PersistentVolumeClaims()
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	podInformer := informerFactory.Core().V1().Pods()
+	nsInformer := informerFactory.Core().V1().Namespaces()
+	scInformer := informerFactory.Storage().V1().StorageClasses()
+	nodeResolver, err := nodes.NewResolver(nodeInformer)
+	if err != nil {
+		return nil, err
+	}
 
 	pvcrInformerFactory := pvcrinformers.NewSharedInformerFactory(pvcrClient, k8sConfig.ResyncPeriod)
-	pvcrInformer := pvcrInformerFactory.Storage().V1().PersistentVolumeClaimRuntimes()
+	pvcrInformer := pvcrInformerFactory.Storage().V2().PersistentVolumeClaimRuntimes()
+	nsrInformer := pvcrInformerFactory.Storage().V2().NamespaceStorageRuntimes()
+	scrInformer := pvcrInformerFactory.Storage().V2().StorageClassRuntimes()
 
 	pvLister := pvInformer.Lister()
 	pvcLister := pvcInformer.Lister()
 	pvcrLister := pvcrInformer.Lister()
 
-	volumeManager := volume.New(volumeConfig, pvcrClient, pvLister, pvcLister, pvcrLister)
-	workloadManager := workload.New(k8sClient, informerFactory, tappManager)
+	var ownerLabels []string
+	for _, key := range strings.Split(cfg.OwnerLabels, ",") {
+		if key = strings.TrimSpace(key); len(key) > 0 {
+			ownerLabels = append(ownerLabels, key)
+		}
+	}
+
+	kubeletUsages := nodes.NewVolumeUsageCollector(nodeInformer.Lister())
+	volumeManager, err := volume.New(volumeConfig, pvcrClient, pvLister, pvcLister, pvcrLister,
+		informerFactory.Storage().V1().VolumeAttachments(), nodeInformer, podInformer, k8sClient.CoreV1(),
+		kubeletUsages)
+	if err != nil {
+		return nil, err
+	}
+	workloadManager := workload.New(informerFactory, tappManager)
+	usageCollector := newUsageCollector(volumeManager, nodeResolver,
+		cfg.UsageHistoryLength, cfg.UsageHistoryResolution, pvcrClient, pvcLister, pvcrLister)
+	var claimSigner *claimSigner
+	if cfg.WebhookConfig.ClaimAdmission {
+		claimSigner, err = newClaimSigner(cfg.WebhookConfig.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pvcrManager := newPVCRManager(volumeManager, pvcLister, pvcrClient, pvcrLister, pvcInformer,
+		nsInformer.Lister(), ownerLabels, claimSigner)
+	podCollector, err := newPodCollector(podInformer,
+		informerFactory.Apps().V1().ReplicaSets().Lister(), pvcrClient, pvcLister, pvcrLister)
+	if err != nil {
+		return nil, err
+	}
+	scCollector, err := newStorageClassCollector(scInformer, pvLister, pvcInformer,
+		pvcrClient, pvcrInformer, scrInformer.Lister())
+	if err != nil {
+		return nil, err
+	}
+	quotaReconciler := newQuotaReconciler(volumeManager, cfg.QuotaReconcileDryRun,
+		pvcrClient, pvcLister, pvcrLister)
 
 	return &manager{
 		k8sClient:           k8sClient,
 		informerFactory:     informerFactory,
 		pvSynced:            pvInformer.Informer().HasSynced,
 		pvcSynced:           pvcInformer.Informer().HasSynced,
+		nodeSynced:          nodeInformer.Informer().HasSynced,
+		podSynced:           podInformer.Informer().HasSynced,
+		nsSynced:            nsInformer.Informer().HasSynced,
+		scSynced:            scInformer.Informer().HasSynced,
 		pvcrInformerFactory: pvcrInformerFactory,
 		pvcrSynced:          pvcrInformer.Informer().HasSynced,
+		nsrSynced:           nsrInformer.Informer().HasSynced,
+		scrSynced:           scrInformer.Informer().HasSynced,
+		crdClient:           crdClient,
 
 		admitor:          newAdmitor(volumeManager, workloadManager),
+		claimAdmitor:     newClaimAdmitor(claimSigner),
 		volumeManager:    volumeManager,
 		workloadManager:  workloadManager,
-		pvcrManager:      newPVCRManager(volumeManager, pvcLister, pvcrClient, pvcrLister, pvcInformer),
-		nodeCollector:    newNodeCollector(volumeManager, pvcrClient, pvcLister, pvcrLister),
-		usageCollector:   newUsageCollector(volumeManager, pvcrClient, pvcLister, pvcrLister),
+		pvcrManager:      pvcrManager,
+		nodeCollector:    newNodeCollector(volumeManager, nodeResolver, pvcrClient, pvcLister, pvcrLister),
+		usageCollector:   usageCollector,
+		podCollector:     podCollector,
+		nsCollector:      newNamespaceCollector(pvcLister, pvcrClient, pvcrInformer, nsrInformer.Lister()),
+		scCollector:      scCollector,
+		quotaReconciler:  quotaReconciler,
 		workloadRecycler: newWorkloadRecycler(workloadManager, pvcrClient, pvcrLister),
+		migrator:         newStorageVersionMigrator(pvcrClient, crdClient),
 
 		tappManager: tappManager,
 	}, nil
@@ -125,13 +209,24 @@ func New(cfg *config.Config) (Manager, error) {
 // Run starts the manager.
 func (m *manager) Run(cfg *config.Config) error {
 	webhookConfig := &cfg.WebhookConfig
+
+	// PersistentVolumeClaimRuntimes stored in other versions can't be read
+	// without the conversion webhook, so all replicas serve it before
+	// starting informers, no matter they are leader or not.
+	go m.serve(webhookConfig)
+	if cfg.SyncCRDConversion {
+		if err := syncCRDConversion(m.crdClient, webhookConfig); err != nil {
+			return fmt.Errorf("sync crd conversion failed: %v", err)
+		}
+	}
+
 	if !cfg.LeaderElection {
-		return m.run(webhookConfig, cfg.Worker, signals.SetupSignalHandler())
+		return m.run(cfg, signals.SetupSignalHandler())
 	}
 
 	run := func(ctx context.Context) {
 		stopCh := ctx.Done()
-		err := m.run(webhookConfig, cfg.Worker, stopCh)
+		err := m.run(cfg, stopCh)
 		if err != nil {
 			{
 				klog.Errorf("Start volume manager failed: %v", err)
@@ -148,11 +243,15 @@ func (m *manager) Run(cfg *config.Config) error {
 }
 
 // run starts the manager.
-func (m *manager) run(webhookCfg *config.WebhookConfig, worker int, stopCh <-chan struct{}) error {
+func (m *manager) run(cfg *config.Config, stopCh <-chan struct{}) error {
+	webhookCfg := &cfg.WebhookConfig
+	worker := cfg.Worker
+
 	m.informerFactory.Start(stopCh)
 	m.pvcrInformerFactory.Start(stopCh)
-	if !cache.WaitForCacheSync(stopCh, m.pvSynced, m.pvcSynced, m.pvcrSynced) {
-		return fmt.Errorf("wait for pv/pvc caches synced timeout")
+	if !cache.WaitForCacheSync(stopCh, m.pvSynced, m.pvcSynced, m.nodeSynced, m.podSynced, m.nsSynced,
+		m.scSynced, m.pvcrSynced, m.nsrSynced, m.scrSynced) {
+		return fmt.Errorf("wait for caches synced timeout")
 	}
 
 	if err := m.tappManager.Start(stopCh); err != nil {
@@ -169,11 +268,20 @@ func (m *manager) run(webhookCfg *config.WebhookConfig, worker int, stopCh <-cha
 	m.pvcrManager.Run(worker, stopCh)
 	m.nodeCollector.Run(worker, stopCh)
 	m.usageCollector.Run(worker, stopCh)
+	m.podCollector.Run(worker, stopCh)
+	m.nsCollector.Run(worker, stopCh)
+	m.scCollector.Run(worker, stopCh)
+	m.quotaReconciler.Run(worker, stopCh)
 	m.workloadRecycler.Run(worker, stopCh)
+	if cfg.MigrateStorageVersion {
+		m.migrator.Run(stopCh)
+	}
 
-	addr := ":443"
-	if len(webhookCfg.URL) > 0 {
-		addr = webhookCfg.URL
+	if webhookCfg.ClaimAdmission {
+		if err := m.syncClaimWebhook(webhookCfg); err != nil {
+			return fmt.Errorf("sync claim webhook failed: %v", err)
+		}
+		klog.Info("Claim admission enabled")
 	}
 
 	if !webhookCfg.WorkloadAdmission {
@@ -182,19 +290,47 @@ func (m *manager) run(webhookCfg *config.WebhookConfig, worker int, stopCh <-cha
 		return nil
 	}
 
-	klog.Info("Workload admission enabled, start webhook server")
+	klog.Info("Workload admission enabled")
+
+	if err := m.syncWebhook(webhookCfg); err != nil {
+		return fmt.Errorf("sync webhook failed: %v", err)
+	}
+	atomic.StoreInt32(&m.admissionReady, 1)
+
+	<-stopCh
+	return nil
+}
+
+// serve starts the webhook server.
+func (m *manager) serve(webhookCfg *config.WebhookConfig) {
+	addr := ":443"
+	if len(webhookCfg.URL) > 0 {
+		addr = webhookCfg.URL
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc(webhookCfg.ValidatingPath, m.admitor.handle)
+	mux.HandleFunc(webhookCfg.ConversionPath, convert)
+	if webhookCfg.ClaimAdmission {
+		mux.HandleFunc(webhookCfg.MutatingPath, m.claimAdmitor.handle)
+	}
+	if webhookCfg.WorkloadAdmission {
+		mux.HandleFunc(webhookCfg.ValidatingPath, m.admit)
+	}
 	server := &http.Server{
 		Addr:      addr,
 		Handler:   mux,
 		TLSConfig: webhookCfg.TLSConfig(),
 	}
 
-	if err := m.syncWebhook(webhookCfg); err != nil {
-		return fmt.Errorf("sync webhook failed: %v", err)
-	}
+	klog.Info("Start webhook server")
+	klog.Fatalf("Webhook server stopped: %v", server.ListenAndServeTLS("", ""))
+}
 
-	return server.ListenAndServeTLS("", "")
+// admit handles an admission request if this replica is ready to admit workloads.
+func (m *manager) admit(w http.ResponseWriter, req *http.Request) {
+	if atomic.LoadInt32(&m.admissionReady) == 0 {
+		response(w, http.StatusServiceUnavailable, "workload admission is not ready")
+		return
+	}
+	m.admitor.handle(w, req)
 }
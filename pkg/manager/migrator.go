@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+)
+
+const (
+	migrationRetryInterval = time.Minute
+	migrationPageSize      = 500
+)
+
+// newStorageVersionMigrator creates a storageVersionMigrator.
+func newStorageVersionMigrator(
+	pvcrClient clientset.Interface,
+	crdClient dynamic.ResourceInterface) *storageVersionMigrator {
+	return &storageVersionMigrator{
+		pvcrClient: pvcrClient,
+		crdClient:  crdClient,
+	}
+}
+
+// storageVersionMigrator rewrites all PersistentVolumeClaimRuntimes in the storage version.
+type storageVersionMigrator struct {
+	pvcrClient clientset.Interface
+	crdClient  dynamic.ResourceInterface
+}
+
+// Run migrates PersistentVolumeClaimRuntimes until succeeded.
+func (m *storageVersionMigrator) Run(stopCh <-chan struct{}) {
+	go func() {
+		_ = wait.PollImmediateUntil(migrationRetryInterval, func() (bool, error) {
+			if err := m.migrate(); err != nil {
+				klog.Errorf("Migrate PVC runtimes to %s failed: %v", storagev2.SchemeGroupVersion, err)
+				return false, nil
+			}
+			return true, nil
+		}, stopCh)
+	}()
+	klog.Infof("Storage version migrator started")
+}
+
+// migrate rewrites all PersistentVolumeClaimRuntimes, then marks the storage
+// version as the only stored version of the crd.
+func (m *storageVersionMigrator) migrate() error {
+	client := m.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(metav1.NamespaceAll)
+	migrated := 0
+	options := metav1.ListOptions{Limit: migrationPageSize}
+	for {
+		list, err := client.List(options)
+		if err != nil {
+			return fmt.Errorf("list pvc runtimes failed: %v", err)
+		}
+		for i := range list.Items {
+			pvcr := &list.Items[i]
+			// An update without any change makes apiserver write the object in the storage version.
+			_, err := m.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).Update(pvcr)
+			if err != nil {
+				// A deleted or concurrently updated object needn't migration.
+				if k8serrors.IsNotFound(err) || k8serrors.IsConflict(err) {
+					continue
+				}
+				return fmt.Errorf("rewrite pvc runtime %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+			}
+			migrated++
+		}
+		if len(list.Continue) == 0 {
+			break
+		}
+		options.Continue = list.Continue
+	}
+	klog.Infof("%d PVC runtimes rewritten in %s", migrated, storagev2.SchemeGroupVersion)
+
+	crd, err := m.crdClient.Get(pvcrCRDName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get crd %s failed: %v", pvcrCRDName, err)
+	}
+	storedVersions := []interface{}{storagev2.SchemeGroupVersion.Version}
+	if err := unstructured.SetNestedSlice(crd.Object, storedVersions, "status", "storedVersions"); err != nil {
+		return fmt.Errorf("set stored versions of crd %s failed: %v", pvcrCRDName, err)
+	}
+	if _, err := m.crdClient.UpdateStatus(crd, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update stored versions of crd %s failed: %v", pvcrCRDName, err)
+	}
+	klog.Infof("Stored versions of crd %s updated to %v", pvcrCRDName, storedVersions)
+
+	return nil
+}
@@ -0,0 +1,293 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"sort"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
+	storageinformers "tkestack.io/volume-decorator/pkg/generated/informers/externalversions/storage/v2"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+	"tkestack.io/volume-decorator/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+const (
+	namespaceSyncInterval = time.Minute
+	// topConsumerCount is the max count of volumes in NamespaceStorageRuntimeStatus.TopConsumers.
+	topConsumerCount = 10
+	// betaStorageClassAnnotation is the deprecated annotation of PVCs to specify the StorageClass.
+	betaStorageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+)
+
+// newNamespaceCollector creates a namespaceCollector.
+func newNamespaceCollector(
+	pvcLister corelisters.PersistentVolumeClaimLister,
+	pvcrClient clientset.Interface,
+	pvcrInformer storageinformers.PersistentVolumeClaimRuntimeInformer,
+	nsrLister pvcrlisters.NamespaceStorageRuntimeLister) *namespaceCollector {
+	queue := workqueue.NewNamedRateLimitingQueue(
+		workqueue.DefaultControllerRateLimiter(), "namespace_collector")
+	c := &namespaceCollector{
+		pvcLister:  pvcLister,
+		pvcrClient: pvcrClient,
+		pvcrLister: pvcrInformer.Lister(),
+		nsrLister:  nsrLister,
+
+		queue: queue,
+	}
+	pvcrInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueue(newObj)
+		},
+		DeleteFunc: c.enqueue,
+	})
+	return c
+}
+
+// namespaceCollector summarizes PVCRs of each namespace into a NamespaceStorageRuntime.
+type namespaceCollector struct {
+	pvcLister  corelisters.PersistentVolumeClaimLister
+	pvcrClient clientset.Interface
+	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister
+	nsrLister  pvcrlisters.NamespaceStorageRuntimeLister
+
+	queue workqueue.RateLimitingInterface
+}
+
+// Run starts the namespaceCollector.
+func (c *namespaceCollector) Run(workers int, stopCh <-chan struct{}) {
+	go wait.Until(c.resync, namespaceSyncInterval, stopCh)
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.syncNamespaces, 0, stopCh)
+	}
+	klog.Infof("Namespace collector started")
+}
+
+// enqueue puts the namespace of a PVCR into the queue.
+func (c *namespaceCollector) enqueue(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pvcr, ok := obj.(*storagev2.PersistentVolumeClaimRuntime); ok {
+		c.queue.Add(pvcr.Namespace)
+	}
+}
+
+// resync puts all namespaces having PVCRs or NamespaceStorageRuntimes into the queue.
+func (c *namespaceCollector) resync() {
+	pvcrs, err := c.pvcrLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("List PVC runtime failed: %v", err)
+		return
+	}
+	for _, pvcr := range pvcrs {
+		c.queue.Add(pvcr.Namespace)
+	}
+
+	nsrs, err := c.nsrLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("List namespace storage runtime failed: %v", err)
+		return
+	}
+	for _, nsr := range nsrs {
+		c.queue.Add(nsr.Namespace)
+	}
+}
+
+// syncNamespaces syncs all namespaces.
+func (c *namespaceCollector) syncNamespaces() {
+	key, quit := c.queue.Get()
+	if quit {
+		return
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncNamespace(key.(string)); err != nil {
+		// Put the namespace back to the queue so that we can retry later.
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+}
+
+// syncNamespace updates the NamespaceStorageRuntime of a namespace. It is
+// deleted if there are no PVCRs in the namespace.
+func (c *namespaceCollector) syncNamespace(namespace string) error {
+	klog.V(4).Infof("Start to process namespace: %s", namespace)
+
+	pvcrs, err := c.pvcrLister.PersistentVolumeClaimRuntimes(namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("List PVC runtime of namespace %s failed: %v", namespace, err)
+		return err
+	}
+	nsr, err := c.nsrLister.NamespaceStorageRuntimes(namespace).Get(namespace)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("Get namespace storage runtime %s failed: %v", namespace, err)
+			return err
+		}
+		nsr = nil
+	}
+
+	nsrClient := c.pvcrClient.StorageV2().NamespaceStorageRuntimes(namespace)
+	if len(pvcrs) == 0 {
+		if nsr == nil {
+			return nil
+		}
+		err := nsrClient.Delete(namespace, &metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("Delete namespace storage runtime %s failed: %v", namespace, err)
+			return err
+		}
+		klog.Infof("Namespace storage runtime %s deleted", namespace)
+		return nil
+	}
+
+	status := c.summarize(pvcrs)
+	if nsr == nil {
+		nsr, err = nsrClient.Create(&storagev2.NamespaceStorageRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace, Namespace: namespace},
+		})
+		if err != nil {
+			klog.Errorf("Create namespace storage runtime %s failed: %v", namespace, err)
+			return err
+		}
+		klog.Infof("Namespace storage runtime %s created", namespace)
+	} else if summaryEqual(&nsr.Status, status) && !needRefresh(nsr.Status.LastUpdated) {
+		return nil
+	}
+
+	now := metav1.Now()
+	newNSR := nsr.DeepCopy()
+	newNSR.Status = *status
+	newNSR.Status.LastUpdated = &now
+	if _, err := nsrClient.UpdateStatus(newNSR); err != nil {
+		klog.Errorf("Update namespace storage runtime %s failed: %v", namespace, err)
+		return err
+	}
+	return nil
+}
+
+// summarize generates the summary of PVCRs.
+func (c *namespaceCollector) summarize(
+	pvcrs []*storagev2.PersistentVolumeClaimRuntime) *storagev2.NamespaceStorageRuntimeStatus {
+	status := &storagev2.NamespaceStorageRuntimeStatus{}
+	classes := make(map[string]*storagev2.StorageClassSummary)
+	var consumers []storagev2.VolumeConsumer
+
+	for _, pvcr := range pvcrs {
+		var requested int64
+		class := ""
+		pvc, err := c.pvcLister.PersistentVolumeClaims(pvcr.Namespace).Get(pvcr.Name)
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				klog.Errorf("Get PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+			}
+		} else {
+			request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			requested = request.Value()
+			class = claimStorageClass(pvc)
+		}
+
+		status.VolumeCount++
+		status.RequestedBytes += requested
+		status.CapacityBytes += pvcr.Status.CapacityBytes
+		status.UsageBytes += pvcr.Status.UsageBytes
+		for _, s := range pvcr.Status.Statuses {
+			if status.StatusCounts == nil {
+				status.StatusCounts = make(map[string]int32)
+			}
+			status.StatusCounts[string(s)]++
+		}
+
+		summary, exist := classes[class]
+		if !exist {
+			summary = &storagev2.StorageClassSummary{StorageClassName: class}
+			classes[class] = summary
+		}
+		summary.VolumeCount++
+		summary.RequestedBytes += requested
+		summary.CapacityBytes += pvcr.Status.CapacityBytes
+		summary.UsageBytes += pvcr.Status.UsageBytes
+
+		if pvcr.Status.UsageBytes > 0 {
+			consumers = append(consumers, storagev2.VolumeConsumer{
+				Name:          pvcr.Name,
+				UsageBytes:    pvcr.Status.UsageBytes,
+				CapacityBytes: pvcr.Status.CapacityBytes,
+				UtilizationPercent: utilizationPercent(&types.VolumeUsage{
+					UsedBytes:     pvcr.Status.UsageBytes,
+					CapacityBytes: pvcr.Status.CapacityBytes,
+				}),
+			})
+		}
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		if consumers[i].UsageBytes != consumers[j].UsageBytes {
+			return consumers[i].UsageBytes > consumers[j].UsageBytes
+		}
+		return consumers[i].Name < consumers[j].Name
+	})
+	if len(consumers) > topConsumerCount {
+		consumers = consumers[:topConsumerCount]
+	}
+	status.TopConsumers = consumers
+
+	for _, summary := range classes {
+		status.StorageClasses = append(status.StorageClasses, *summary)
+	}
+	sort.Slice(status.StorageClasses, func(i, j int) bool {
+		return status.StorageClasses[i].StorageClassName < status.StorageClasses[j].StorageClassName
+	})
+
+	return status
+}
+
+// summaryEqual returns true if two summaries are equal, LastUpdated is ignored.
+func summaryEqual(s1, s2 *storagev2.NamespaceStorageRuntimeStatus) bool {
+	c1, c2 := s1.DeepCopy(), s2.DeepCopy()
+	c1.LastUpdated, c2.LastUpdated = nil, nil
+	return equality.Semantic.DeepEqual(c1, c2)
+}
+
+// claimStorageClass returns the StorageClass of a PVC, or an empty string if not specified.
+func claimStorageClass(pvc *corev1.PersistentVolumeClaim) string {
+	if class, exist := pvc.Annotations[betaStorageClassAnnotation]; exist {
+		return class
+	}
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return ""
+}
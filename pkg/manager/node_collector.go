@@ -18,13 +18,16 @@
 package manager
 
 import (
+	"sort"
 	"time"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
-	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v1"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+	"tkestack.io/volume-decorator/pkg/nodes"
 	"tkestack.io/volume-decorator/pkg/volume"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 )
@@ -34,11 +37,13 @@ const nodeSyncInterval = time.Second * 5
 // newNodeCollector creates a nodeCollector.
 func newNodeCollector(
 	volumeManager volume.Manager,
+	nodeResolver *nodes.Resolver,
 	pvcrClient clientset.Interface,
 	pvcLister corelisters.PersistentVolumeClaimLister,
 	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister) *nodeCollector {
-	c := &nodeCollector{volumeManager: volumeManager}
-	c.controller = newController("node-collector", c.update, nodeSyncInterval, pvcrClient, pvcLister, pvcrLister)
+	c := &nodeCollector{volumeManager: volumeManager, nodeResolver: nodeResolver}
+	c.controller = newController("node-collector", storagev2.RuntimeConditionMountedNodesUnavailable, c.update,
+		nodeSyncInterval, pvcrClient, pvcLister, pvcrLister)
 	return c
 }
 
@@ -46,24 +51,80 @@ func newNodeCollector(
 type nodeCollector struct {
 	*controller
 	volumeManager volume.Manager
+	nodeResolver  *nodes.Resolver
 }
 
 // update collects mounted nodes of a volume and updates according PVCR.
 func (c *nodeCollector) update(
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) (*storagev1alpha1.PersistentVolumeClaimRuntime, error) {
-	nodes, err := c.volumeManager.MountedNodes(pvcr.Namespace, pvcr.Name)
+	pvcr *storagev2.PersistentVolumeClaimRuntime) (*storagev2.PersistentVolumeClaimRuntime, error) {
+	mountedNodes, err := c.volumeManager.MountedNodes(pvcr.Namespace, pvcr.Name)
 	if err != nil {
 		klog.Errorf("Check mounted node for PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
 		return nil, err
 	}
-	if arrayEqual(nodes, pvcr.Spec.MountedNodes) {
+	for i := range mountedNodes {
+		node := &mountedNodes[i]
+		if len(node.NodeName) > 0 {
+			continue
+		}
+		if node.NodeName, err = c.nodeResolver.NodeName(node.Address); err != nil {
+			klog.Errorf("Resolve node of address %s failed: %v", node.Address, err)
+		}
+	}
+
+	changed := !mountedNodesEqual(mountedNodes, pvcr.Status.MountedNodes)
+	if !changed && !needRefresh(pvcr.Status.LastUpdated.MountedNodes) {
 		return nil, nil
 	}
-	klog.Infof("Mounted nodes of PVC %s/%s changed: %v -> %v", pvcr.Namespace, pvcr.Name, pvcr.Spec.MountedNodes, nodes)
+	if changed {
+		klog.Infof("Mounted nodes of PVC %s/%s changed: %v -> %v", pvcr.Namespace, pvcr.Name,
+			mountedNodeAddresses(pvcr.Status.MountedNodes), mountedNodeAddresses(mountedNodes))
+	}
 
+	now := metav1.Now()
 	newPVCR := pvcr.DeepCopy()
-	newPVCR.Spec.MountedNodes = nodes
+	newPVCR.Status.MountedNodes = mergeMountedNodes(pvcr.Status.MountedNodes, mountedNodes, now)
+	newPVCR.Status.LastUpdated.MountedNodes = &now
 	updatePVCStatus(newPVCR)
 
 	return newPVCR, nil
 }
+
+// mergeMountedNodes generates the mount records from the current mounted nodes,
+// FirstSeen is kept from the old record of the same address.
+func mergeMountedNodes(oldNodes, nodes []storagev2.MountedNode, now metav1.Time) []storagev2.MountedNode {
+	firstSeen := make(map[string]*metav1.Time, len(oldNodes))
+	for i := range oldNodes {
+		firstSeen[oldNodes[i].Address] = oldNodes[i].FirstSeen
+	}
+
+	result := make([]storagev2.MountedNode, 0, len(nodes))
+	for _, node := range nodes {
+		node.FirstSeen = firstSeen[node.Address]
+		if node.FirstSeen == nil {
+			node.FirstSeen = &now
+		}
+		node.LastSeen = &now
+		result = append(result, node)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Address < result[j].Address })
+
+	return result
+}
+
+// mountedNodesEqual returns true if two lists have the same mounts, timestamps are ignored.
+func mountedNodesEqual(nodes1, nodes2 []storagev2.MountedNode) bool {
+	if len(nodes1) != len(nodes2) {
+		return false
+	}
+	set := make(map[storagev2.MountedNode]bool, len(nodes1))
+	for _, node := range nodes1 {
+		set[storagev2.MountedNode{Address: node.Address, NodeName: node.NodeName, AccessMode: node.AccessMode}] = true
+	}
+	for _, node := range nodes2 {
+		if !set[storagev2.MountedNode{Address: node.Address, NodeName: node.NodeName, AccessMode: node.AccessMode}] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+	"tkestack.io/volume-decorator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+const podSyncInterval = time.Minute
+
+// newPodCollector creates a podCollector, it must be called before the informers started.
+func newPodCollector(
+	podInformer coreinformers.PodInformer,
+	rsLister appslisters.ReplicaSetLister,
+	pvcrClient clientset.Interface,
+	pvcLister corelisters.PersistentVolumeClaimLister,
+	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister) (*podCollector, error) {
+	informer := podInformer.Informer()
+	if err := util.AddPodClaimIndex(informer); err != nil {
+		return nil, err
+	}
+
+	c := &podCollector{podIndexer: informer.GetIndexer(), rsLister: rsLister}
+	c.controller = newController("pod-collector", storagev2.RuntimeConditionPodsUnavailable, c.update,
+		podSyncInterval, pvcrClient, pvcLister, pvcrLister)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueuePod,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueuePod(newObj)
+		},
+		DeleteFunc: c.enqueuePod,
+	})
+	return c, nil
+}
+
+// podCollector is a collector to collect the pods consuming a volume.
+type podCollector struct {
+	*controller
+	podIndexer cache.Indexer
+	rsLister   appslisters.ReplicaSetLister
+}
+
+// update collects consuming pods of a volume and records them under their workloads.
+func (c *podCollector) update(
+	pvcr *storagev2.PersistentVolumeClaimRuntime) (*storagev2.PersistentVolumeClaimRuntime, error) {
+	objs, err := c.podIndexer.ByIndex(util.PodClaimIndex, pvcr.Namespace+"/"+pvcr.Name)
+	if err != nil {
+		return nil, fmt.Errorf("search pods of PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+	}
+
+	workloadPods := make(map[string][]storagev2.ConsumingPod)
+	for _, obj := range objs {
+		pod := obj.(*corev1.Pod)
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		consumingPod := storagev2.ConsumingPod{
+			Name:       pod.Name,
+			NodeName:   pod.Spec.NodeName,
+			Phase:      pod.Status.Phase,
+			Containers: claimContainers(pod, pvcr.Name),
+		}
+		for _, key := range c.workloadKeys(pod) {
+			if _, exist := pvcr.Status.Workloads[key]; exist {
+				workloadPods[key] = append(workloadPods[key], consumingPod)
+			}
+		}
+	}
+
+	workloads := make(map[string]storagev2.Workload, len(pvcr.Status.Workloads))
+	for key, w := range pvcr.Status.Workloads {
+		w = *w.DeepCopy()
+		w.Pods = workloadPods[key]
+		sort.Slice(w.Pods, func(i, j int) bool { return w.Pods[i].Name < w.Pods[j].Name })
+		workloads[key] = w
+	}
+	if equality.Semantic.DeepEqual(workloads, pvcr.Status.Workloads) {
+		return nil, nil
+	}
+	klog.V(4).Infof("Consuming pods of PVC %s/%s changed", pvcr.Namespace, pvcr.Name)
+
+	now := metav1.Now()
+	newPVCR := pvcr.DeepCopy()
+	newPVCR.Status.Workloads = workloads
+	newPVCR.Status.LastUpdated.Workloads = &now
+
+	return newPVCR, nil
+}
+
+// workloadKeys returns keys of the workloads a pod may belong to: the pod itself,
+// its controller, and the controller of its ReplicaSet, for example a Deployment.
+func (c *podCollector) workloadKeys(pod *corev1.Pod) []string {
+	keys := []string{string(pod.UID)}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return keys
+	}
+	keys = append(keys, string(owner.UID))
+	if owner.Kind != "ReplicaSet" {
+		return keys
+	}
+
+	rs, err := c.rsLister.ReplicaSets(pod.Namespace).Get(owner.Name)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("Get ReplicaSet %s/%s failed: %v", pod.Namespace, owner.Name, err)
+		}
+		return keys
+	}
+	if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil {
+		keys = append(keys, string(rsOwner.UID))
+	}
+	return keys
+}
+
+// enqueuePod puts the PVCs used by a pod into the queue.
+func (c *podCollector) enqueuePod(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	for _, key := range util.PodClaimKeys(obj) {
+		c.queue.Add(key)
+	}
+}
+
+// claimContainers returns names of the containers in a pod which mount the PVC.
+func claimContainers(pod *corev1.Pod, claimName string) []string {
+	volumes := sets.NewString()
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claimName {
+			volumes.Insert(volume.Name)
+		}
+	}
+
+	var containers []string
+	for _, list := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range list {
+			for _, mount := range container.VolumeMounts {
+				if volumes.Has(mount.Name) {
+					containers = append(containers, container.Name)
+					break
+				}
+			}
+		}
+	}
+	return containers
+}
@@ -18,12 +18,15 @@
 package manager
 
 import (
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	"fmt"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
-	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v1"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
 	"tkestack.io/volume-decorator/pkg/volume"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -40,6 +43,11 @@ type pvcrManager struct {
 	pvcLister     corelisters.PersistentVolumeClaimLister
 	pvcrClient    clientset.Interface
 	pvcrLister    pvcrlisters.PersistentVolumeClaimRuntimeLister
+	nsLister      corelisters.NamespaceLister
+	// ownerLabels are keys of the namespace labels inherited by PVCRs.
+	ownerLabels []string
+	// claimSigner checks the creators of PVCs, it is nil if the claim admission is disabled.
+	claimSigner *claimSigner
 
 	queue workqueue.RateLimitingInterface
 }
@@ -50,7 +58,10 @@ func newPVCRManager(
 	pvcLister corelisters.PersistentVolumeClaimLister,
 	pvcrClient clientset.Interface,
 	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister,
-	pvcInformer coreinformers.PersistentVolumeClaimInformer) *pvcrManager {
+	pvcInformer coreinformers.PersistentVolumeClaimInformer,
+	nsLister corelisters.NamespaceLister,
+	ownerLabels []string,
+	claimSigner *claimSigner) *pvcrManager {
 	queue := workqueue.NewNamedRateLimitingQueue(
 		workqueue.DefaultControllerRateLimiter(), "status_updater")
 	u := &pvcrManager{
@@ -58,6 +69,9 @@ func newPVCRManager(
 		pvcLister:     pvcLister,
 		pvcrClient:    pvcrClient,
 		pvcrLister:    pvcrLister,
+		nsLister:      nsLister,
+		ownerLabels:   ownerLabels,
+		claimSigner:   claimSigner,
 
 		queue: queue,
 	}
@@ -146,7 +160,12 @@ func (u *pvcrManager) createPVCR(pvc *corev1.PersistentVolumeClaim) error {
 		klog.Errorf("Get status of PVC %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
 		return err
 	}
-	pvcr := &storagev1alpha1.PersistentVolumeClaimRuntime{
+	spec, err := u.ownerSpec(pvc)
+	if err != nil {
+		klog.Errorf("Get owner of PVC %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
+		return err
+	}
+	pvcr := &storagev2.PersistentVolumeClaimRuntime{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pvc.Name,
 			Namespace: pvc.Namespace,
@@ -159,13 +178,21 @@ func (u *pvcrManager) createPVCR(pvc *corev1.PersistentVolumeClaim) error {
 				},
 			},
 		},
-		Spec: storagev1alpha1.PersistentVolumeClaimRuntimeSpec{
-			Statuses: statuses,
-		},
+		Spec: spec,
 	}
-	_, err = u.pvcrClient.StorageV1().PersistentVolumeClaimRuntimes(pvcr.Namespace).Create(pvcr)
+	created, err := u.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).Create(pvcr)
 	if err != nil {
 		klog.Errorf("Create PVC runtime %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		return err
+	}
+
+	// Status is ignored when creating, so we need to set it through the status subresource.
+	newPVCR := created.DeepCopy()
+	newPVCR.Status.ObservedGeneration = created.Generation
+	newPVCR.Status.SetStatuses(statuses)
+	_, err = u.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).UpdateStatus(newPVCR)
+	if err != nil {
+		klog.Errorf("Update status of PVC runtime %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
 	}
 	return err
 }
@@ -173,22 +200,81 @@ func (u *pvcrManager) createPVCR(pvc *corev1.PersistentVolumeClaim) error {
 // updatePVCR updates a PVCR.
 func (u *pvcrManager) updatePVCR(
 	pvc *corev1.PersistentVolumeClaim,
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) error {
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
 	statuses, err := u.volumeManager.Status(pvc.Namespace, pvc.Name)
 	if err != nil {
 		klog.Errorf("Get status of PVC %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
 		return err
 	}
+	if pvcr, err = u.updateOwner(pvc, pvcr); err != nil {
+		return err
+	}
 
 	newPVCR := pvcr.DeepCopy()
-	newPVCR.Spec.Statuses = statuses
-	_, err = u.pvcrClient.StorageV1().PersistentVolumeClaimRuntimes(pvcr.Namespace).Update(newPVCR)
+	newPVCR.Status.ObservedGeneration = pvcr.Generation
+	newPVCR.Status.SetStatuses(statuses)
+	if equality.Semantic.DeepEqual(pvcr.Status, newPVCR.Status) {
+		return nil
+	}
+	_, err = u.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).UpdateStatus(newPVCR)
 	if err != nil {
-		klog.Errorf("Update PVC runtime %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		klog.Errorf("Update status of PVC runtime %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
 	}
 	return err
 }
 
+// updateOwner updates the ownership in the spec of a PVCR if it is changed.
+// The creator is never changed once it is recorded.
+func (u *pvcrManager) updateOwner(
+	pvc *corev1.PersistentVolumeClaim,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) (*storagev2.PersistentVolumeClaimRuntime, error) {
+	spec, err := u.ownerSpec(pvc)
+	if err != nil {
+		klog.Errorf("Get owner of PVC %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
+		return nil, err
+	}
+	if pvcr.Spec.Creator != nil {
+		spec.Creator = pvcr.Spec.Creator
+	}
+	if equality.Semantic.DeepEqual(pvcr.Spec, spec) {
+		return pvcr, nil
+	}
+
+	newPVCR := pvcr.DeepCopy()
+	newPVCR.Spec = spec
+	updated, err := u.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).Update(newPVCR)
+	if err != nil {
+		klog.Errorf("Update owner of PVC runtime %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ownerSpec generates the ownership of a PVC: the creator recorded and signed by
+// the claim admission, and the owner labels of its namespace.
+func (u *pvcrManager) ownerSpec(pvc *corev1.PersistentVolumeClaim) (storagev2.PersistentVolumeClaimRuntimeSpec, error) {
+	spec := storagev2.PersistentVolumeClaimRuntimeSpec{Creator: u.claimSigner.creator(pvc)}
+	if len(u.ownerLabels) == 0 {
+		return spec, nil
+	}
+
+	ns, err := u.nsLister.Get(pvc.Namespace)
+	if err != nil {
+		return spec, fmt.Errorf("get namespace %s failed: %v", pvc.Namespace, err)
+	}
+	for _, key := range u.ownerLabels {
+		value, exist := ns.Labels[key]
+		if !exist {
+			continue
+		}
+		if spec.OwnerLabels == nil {
+			spec.OwnerLabels = make(map[string]string)
+		}
+		spec.OwnerLabels[key] = value
+	}
+	return spec, nil
+}
+
 // getPVCKey generates a unique key for a PVC object.
 func getPVCKey(obj interface{}) (string, error) {
 	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
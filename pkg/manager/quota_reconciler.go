@@ -0,0 +1,138 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+	"tkestack.io/volume-decorator/pkg/volume"
+
+	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog"
+)
+
+const quotaSyncInterval = time.Minute * 5
+
+// newQuotaReconciler creates a quotaReconciler, the drifted quotas are only reported if dryRun is true.
+func newQuotaReconciler(
+	volumeManager volume.Manager,
+	dryRun bool,
+	pvcrClient clientset.Interface,
+	pvcLister corelisters.PersistentVolumeClaimLister,
+	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister) *quotaReconciler {
+	c := &quotaReconciler{
+		volumeManager: volumeManager,
+		dryRun:        dryRun,
+	}
+	c.controller = newController("quota-reconciler", storagev2.RuntimeConditionQuotaUnavailable, c.update,
+		quotaSyncInterval, pvcrClient, pvcLister, pvcrLister)
+	return c
+}
+
+// quotaReconciler compares the quota enforced by the storage backend with the capacity of a PVC,
+// which may be lost or wrong after manual moves, old provisioners or failed expansions. The drift
+// is reported by the QuotaDrift condition, and fixed by growing the quota to the capacity. Quotas
+// are never shrunk, since a quota larger than the capacity is usually left by an expansion which
+// only failed to update the PVC, and the data may not fit into the capacity any more.
+type quotaReconciler struct {
+	*controller
+	volumeManager volume.Manager
+	dryRun        bool
+}
+
+// update reports and fixes the quota drift of a volume.
+func (c *quotaReconciler) update(
+	pvcr *storagev2.PersistentVolumeClaimRuntime) (*storagev2.PersistentVolumeClaimRuntime, error) {
+	quota, err := c.volumeManager.Quota(pvcr.Namespace, pvcr.Name)
+	if err != nil {
+		klog.Errorf("Get quota for PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		return nil, err
+	}
+	if quota == nil {
+		return nil, nil
+	}
+	pvc, err := c.pvcLister.PersistentVolumeClaims(pvcr.Namespace).Get(pvcr.Name)
+	if err != nil {
+		klog.Errorf("Get PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		return nil, err
+	}
+	capacity, exist := pvc.Status.Capacity[corev1.ResourceStorage]
+	// The quota is changed before the capacity while expanding.
+	if !exist || storagev2.HasStatus(pvcr.Status.Statuses, storagev2.ClaimStatusExpanding) {
+		return nil, nil
+	}
+
+	condition := storagev2.PersistentVolumeClaimRuntimeCondition{
+		Type:   storagev2.RuntimeConditionQuotaDrift,
+		Status: corev1.ConditionFalse,
+	}
+	if *quota != capacity.Value() {
+		condition = c.reconcile(pvcr, *quota, capacity.Value())
+	}
+	newPVCR := pvcr.DeepCopy()
+	newPVCR.Status.SetCondition(condition)
+	return newPVCR, nil
+}
+
+// reconcile grows the quota of a volume to its capacity unless in dry run, and returns the QuotaDrift condition.
+func (c *quotaReconciler) reconcile(
+	pvcr *storagev2.PersistentVolumeClaimRuntime,
+	quota, capacity int64) storagev2.PersistentVolumeClaimRuntimeCondition {
+	condition := storagev2.PersistentVolumeClaimRuntimeCondition{
+		Type:    storagev2.RuntimeConditionQuotaDrift,
+		Status:  corev1.ConditionTrue,
+		Reason:  "QuotaMismatch",
+		Message: fmt.Sprintf("Quota is %d bytes, but the capacity is %d bytes", quota, capacity),
+	}
+	if quota == 0 {
+		condition.Reason = "QuotaMissing"
+		condition.Message = fmt.Sprintf("No quota is set, the capacity is %d bytes", capacity)
+	}
+	if quota > capacity {
+		condition.Reason = "QuotaExceedsCapacity"
+		klog.V(4).Infof("Quota of PVC %s/%s drifted: %s", pvcr.Namespace, pvcr.Name, condition.Message)
+		return condition
+	}
+	if quota == 0 && pvcr.Status.UsageBytes > capacity {
+		condition.Message = fmt.Sprintf("%s, but %d bytes are used", condition.Message, pvcr.Status.UsageBytes)
+		klog.V(4).Infof("Quota of PVC %s/%s drifted: %s", pvcr.Namespace, pvcr.Name, condition.Message)
+		return condition
+	}
+	if c.dryRun {
+		klog.V(4).Infof("Quota of PVC %s/%s drifted: %s", pvcr.Namespace, pvcr.Name, condition.Message)
+		return condition
+	}
+
+	if err := c.volumeManager.SetQuota(pvcr.Namespace, pvcr.Name, capacity); err != nil {
+		klog.Errorf("Set quota for PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		condition.Message = fmt.Sprintf("%s, fixing failed: %v", condition.Message, err)
+		return condition
+	}
+	klog.Infof("Quota of PVC %s/%s fixed: %d -> %d bytes", pvcr.Namespace, pvcr.Name, quota, capacity)
+	return storagev2.PersistentVolumeClaimRuntimeCondition{
+		Type:    storagev2.RuntimeConditionQuotaDrift,
+		Status:  corev1.ConditionFalse,
+		Reason:  "QuotaFixed",
+		Message: fmt.Sprintf("Quota is set from %d to %d bytes", quota, capacity),
+	}
+}
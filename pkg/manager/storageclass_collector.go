@@ -0,0 +1,375 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
+	storageinformers "tkestack.io/volume-decorator/pkg/generated/informers/externalversions/storage/v2"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	scinformers "k8s.io/client-go/informers/storage/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+const (
+	storageClassSyncInterval = time.Minute
+	// claimStorageClassIndex is the name of the index from StorageClass names to the PVCs of them.
+	claimStorageClassIndex = "storageClass"
+	// poolParameter is the parameter of StorageClasses and the volume attribute
+	// of CSI PVs specifying the backend pool.
+	poolParameter = "pool"
+)
+
+// newStorageClassCollector creates a storageClassCollector, it must be called before the informers started.
+func newStorageClassCollector(
+	scInformer scinformers.StorageClassInformer,
+	pvLister corelisters.PersistentVolumeLister,
+	pvcInformer coreinformers.PersistentVolumeClaimInformer,
+	pvcrClient clientset.Interface,
+	pvcrInformer storageinformers.PersistentVolumeClaimRuntimeInformer,
+	scrLister pvcrlisters.StorageClassRuntimeLister) (*storageClassCollector, error) {
+	informer := pvcInformer.Informer()
+	if _, exist := informer.GetIndexer().GetIndexers()[claimStorageClassIndex]; !exist {
+		if err := informer.AddIndexers(cache.Indexers{claimStorageClassIndex: indexClaimStorageClass}); err != nil {
+			return nil, fmt.Errorf("add pvc storage class indexer failed: %v", err)
+		}
+	}
+	queue := workqueue.NewNamedRateLimitingQueue(
+		workqueue.DefaultControllerRateLimiter(), "storage_class_collector")
+	c := &storageClassCollector{
+		scLister:   scInformer.Lister(),
+		pvLister:   pvLister,
+		pvcLister:  pvcInformer.Lister(),
+		pvcIndexer: informer.GetIndexer(),
+		pvcrClient: pvcrClient,
+		pvcrLister: pvcrInformer.Lister(),
+		scrLister:  scrLister,
+
+		queue: queue,
+	}
+	scInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueueClass,
+		DeleteFunc: c.enqueueClass,
+	})
+	// The PVC may be already deleted when its PVCR is deleted, so
+	// the StorageClass is also synced when a PVC is deleted.
+	pvcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: c.enqueueClaim,
+	})
+	pvcrInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueuePVCR,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Most updates, such as the usage history, don't change the summary.
+			if !pvcrSummaryEqual(oldObj.(*storagev2.PersistentVolumeClaimRuntime),
+				newObj.(*storagev2.PersistentVolumeClaimRuntime)) {
+				c.enqueuePVCR(newObj)
+			}
+		},
+		DeleteFunc: c.enqueuePVCR,
+	})
+	return c, nil
+}
+
+// storageClassCollector summarizes PVCRs of each StorageClass into a StorageClassRuntime.
+type storageClassCollector struct {
+	scLister   storagelisters.StorageClassLister
+	pvLister   corelisters.PersistentVolumeLister
+	pvcLister  corelisters.PersistentVolumeClaimLister
+	pvcIndexer cache.Indexer
+	pvcrClient clientset.Interface
+	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister
+	scrLister  pvcrlisters.StorageClassRuntimeLister
+
+	queue workqueue.RateLimitingInterface
+}
+
+// Run starts the storageClassCollector.
+func (c *storageClassCollector) Run(workers int, stopCh <-chan struct{}) {
+	go wait.Until(c.resync, storageClassSyncInterval, stopCh)
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.syncStorageClasses, 0, stopCh)
+	}
+	klog.Infof("StorageClass collector started")
+}
+
+// enqueueClass puts a StorageClass into the queue.
+func (c *storageClassCollector) enqueueClass(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if class, ok := obj.(*storagev1.StorageClass); ok {
+		c.queue.Add(class.Name)
+	}
+}
+
+// enqueueClaim puts the StorageClass of a PVC into the queue.
+func (c *storageClassCollector) enqueueClaim(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+		if class := claimStorageClass(pvc); len(class) > 0 {
+			c.queue.Add(class)
+		}
+	}
+}
+
+// enqueuePVCR puts the StorageClass of a PVCR into the queue.
+func (c *storageClassCollector) enqueuePVCR(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pvcr, ok := obj.(*storagev2.PersistentVolumeClaimRuntime)
+	if !ok {
+		return
+	}
+	pvc, err := c.pvcLister.PersistentVolumeClaims(pvcr.Namespace).Get(pvcr.Name)
+	if err != nil {
+		// The StorageClass will be synced by enqueueClaim or resync.
+		return
+	}
+	c.enqueueClaim(pvc)
+}
+
+// resync puts all StorageClasses and StorageClassRuntimes into the queue.
+func (c *storageClassCollector) resync() {
+	classes, err := c.scLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("List StorageClass failed: %v", err)
+		return
+	}
+	for _, class := range classes {
+		c.queue.Add(class.Name)
+	}
+
+	scrs, err := c.scrLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("List StorageClass runtime failed: %v", err)
+		return
+	}
+	for _, scr := range scrs {
+		c.queue.Add(scr.Name)
+	}
+}
+
+// syncStorageClasses syncs all StorageClasses.
+func (c *storageClassCollector) syncStorageClasses() {
+	key, quit := c.queue.Get()
+	if quit {
+		return
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncStorageClass(key.(string)); err != nil {
+		// Put the StorageClass back to the queue so that we can retry later.
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+}
+
+// syncStorageClass updates the StorageClassRuntime of a StorageClass. It is
+// deleted if the StorageClass is deleted.
+func (c *storageClassCollector) syncStorageClass(name string) error {
+	klog.V(4).Infof("Start to process StorageClass: %s", name)
+
+	scr, err := c.scrLister.Get(name)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("Get StorageClass runtime %s failed: %v", name, err)
+			return err
+		}
+		scr = nil
+	}
+
+	scrClient := c.pvcrClient.StorageV2().StorageClassRuntimes()
+	class, err := c.scLister.Get(name)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("Get StorageClass %s failed: %v", name, err)
+			return err
+		}
+		if scr == nil {
+			return nil
+		}
+		err := scrClient.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("Delete StorageClass runtime %s failed: %v", name, err)
+			return err
+		}
+		klog.Infof("StorageClass runtime %s deleted", name)
+		return nil
+	}
+
+	status, err := c.summarize(class)
+	if err != nil {
+		return err
+	}
+	if scr == nil {
+		scr, err = scrClient.Create(&storagev2.StorageClassRuntime{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(class, storagev1.SchemeGroupVersion.WithKind("StorageClass")),
+				},
+			},
+		})
+		if err != nil {
+			klog.Errorf("Create StorageClass runtime %s failed: %v", name, err)
+			return err
+		}
+		klog.Infof("StorageClass runtime %s created", name)
+	} else if storageClassSummaryEqual(&scr.Status, status) && !needRefresh(scr.Status.LastUpdated) {
+		return nil
+	}
+
+	now := metav1.Now()
+	newSCR := scr.DeepCopy()
+	newSCR.Status = *status
+	newSCR.Status.LastUpdated = &now
+	if _, err := scrClient.UpdateStatus(newSCR); err != nil {
+		klog.Errorf("Update StorageClass runtime %s failed: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// summarize generates the summary of PVCRs of a StorageClass.
+func (c *storageClassCollector) summarize(
+	class *storagev1.StorageClass) (*storagev2.StorageClassRuntimeStatus, error) {
+	pvcs, err := c.pvcIndexer.ByIndex(claimStorageClassIndex, class.Name)
+	if err != nil {
+		klog.Errorf("Search PVCs of StorageClass %s failed: %v", class.Name, err)
+		return nil, err
+	}
+
+	status := &storagev2.StorageClassRuntimeStatus{}
+	workloads := sets.NewString()
+	pools := sets.NewString()
+	if pool := class.Parameters[poolParameter]; len(pool) > 0 {
+		pools.Insert(pool)
+	}
+
+	for _, obj := range pvcs {
+		pvc := obj.(*corev1.PersistentVolumeClaim)
+		pvcr, err := c.pvcrLister.PersistentVolumeClaimRuntimes(pvc.Namespace).Get(pvc.Name)
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				klog.Errorf("Get PVC runtime %s/%s failed: %v", pvc.Namespace, pvc.Name, err)
+			}
+			continue
+		}
+
+		status.VolumeCount++
+		status.CapacityBytes += pvcr.Status.CapacityBytes
+		status.UsageBytes += pvcr.Status.UsageBytes
+		for _, s := range pvcr.Status.Statuses {
+			if status.StatusCounts == nil {
+				status.StatusCounts = make(map[string]int32)
+			}
+			status.StatusCounts[string(s)]++
+		}
+		for key := range pvcr.Status.Workloads {
+			workloads.Insert(key)
+		}
+
+		if len(pvc.Spec.VolumeName) == 0 {
+			continue
+		}
+		pv, err := c.pvLister.Get(pvc.Spec.VolumeName)
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				klog.Errorf("Get PV %s failed: %v", pvc.Spec.VolumeName, err)
+			}
+			continue
+		}
+		capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+		status.ProvisionedBytes += capacity.Value()
+		if pool := volumePool(pv); len(pool) > 0 {
+			pools.Insert(pool)
+		}
+	}
+
+	status.WorkloadCount = int32(workloads.Len())
+	status.Pools = pools.List()
+
+	return status, nil
+}
+
+// indexClaimStorageClass indexes PVCs by their StorageClasses.
+func indexClaimStorageClass(obj interface{}) ([]string, error) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return nil, nil
+	}
+	if class := claimStorageClass(pvc); len(class) > 0 {
+		return []string{class}, nil
+	}
+	return nil, nil
+}
+
+// pvcrSummaryEqual returns true if two PVCRs contribute the same to the summary of their StorageClass.
+func pvcrSummaryEqual(p1, p2 *storagev2.PersistentVolumeClaimRuntime) bool {
+	if p1.Status.CapacityBytes != p2.Status.CapacityBytes || p1.Status.UsageBytes != p2.Status.UsageBytes ||
+		len(p1.Status.Workloads) != len(p2.Status.Workloads) ||
+		!equality.Semantic.DeepEqual(p1.Status.Statuses, p2.Status.Statuses) {
+		return false
+	}
+	for key := range p2.Status.Workloads {
+		if _, exist := p1.Status.Workloads[key]; !exist {
+			return false
+		}
+	}
+	return true
+}
+
+// storageClassSummaryEqual returns true if two summaries are equal, LastUpdated is ignored.
+func storageClassSummaryEqual(s1, s2 *storagev2.StorageClassRuntimeStatus) bool {
+	c1, c2 := s1.DeepCopy(), s2.DeepCopy()
+	c1.LastUpdated, c2.LastUpdated = nil, nil
+	return equality.Semantic.DeepEqual(c1, c2)
+}
+
+// volumePool returns the backend pool of a PV, or an empty string if unknown.
+func volumePool(pv *corev1.PersistentVolume) string {
+	switch {
+	case pv.Spec.CSI != nil:
+		return pv.Spec.CSI.VolumeAttributes[poolParameter]
+	case pv.Spec.RBD != nil:
+		return pv.Spec.RBD.RBDPool
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"testing"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	pvcrfake "tkestack.io/volume-decorator/pkg/generated/clientset/versioned/fake"
+	pvcrinformers "tkestack.io/volume-decorator/pkg/generated/informers/externalversions"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStorageClassSummarize(t *testing.T) {
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	pvcrClient := pvcrfake.NewSimpleClientset()
+	pvcrFactory := pvcrinformers.NewSharedInformerFactory(pvcrClient, 0)
+	pvs := factory.Core().V1().PersistentVolumes()
+	pvcs := factory.Core().V1().PersistentVolumeClaims()
+	pvcrs := pvcrFactory.Storage().V2().PersistentVolumeClaimRuntimes()
+	c, err := newStorageClassCollector(factory.Storage().V1().StorageClasses(), pvs.Lister(), pvcs,
+		pvcrClient, pvcrs, pvcrFactory.Storage().V2().StorageClassRuntimes().Lister())
+	if err != nil {
+		t.Fatalf("Create collector failed: %v", err)
+	}
+
+	for name, class := range map[string]string{"data-0": "ssd", "data-1": "ssd", "data-2": "hdd"} {
+		className := class
+		pvcs.Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &className, VolumeName: "pv-" + name},
+		})
+		pvcrs.Informer().GetIndexer().Add(&storagev2.PersistentVolumeClaimRuntime{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Status: storagev2.PersistentVolumeClaimRuntimeStatus{
+				CapacityBytes: 100,
+				UsageBytes:    10,
+				Workloads:     map[string]storagev2.Workload{"uid-" + name: {}},
+			},
+		})
+		pvs.Informer().GetIndexer().Add(&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-" + name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("128")},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					RBD: &corev1.RBDPersistentVolumeSource{RBDPool: class},
+				},
+			},
+		})
+	}
+
+	status, err := c.summarize(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "ssd"}})
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if status.VolumeCount != 2 || status.CapacityBytes != 200 || status.UsageBytes != 20 ||
+		status.ProvisionedBytes != 256 || status.WorkloadCount != 2 ||
+		len(status.Pools) != 1 || status.Pools[0] != "ssd" {
+		t.Errorf("Unexpected summary: %+v", status)
+	}
+}
+
+func TestPVCRSummaryEqual(t *testing.T) {
+	pvcr := &storagev2.PersistentVolumeClaimRuntime{
+		Status: storagev2.PersistentVolumeClaimRuntimeStatus{
+			CapacityBytes: 100,
+			UsageBytes:    10,
+			Workloads:     map[string]storagev2.Workload{"uid-1": {}},
+		},
+	}
+	history := pvcr.DeepCopy()
+	now := metav1.Now()
+	history.Status.FullAt = &now
+	if !pvcrSummaryEqual(pvcr, history) {
+		t.Errorf("Fields out of the summary are compared")
+	}
+	workload := pvcr.DeepCopy()
+	workload.Status.Workloads = map[string]storagev2.Workload{"uid-2": {}}
+	if pvcrSummaryEqual(pvcr, workload) {
+		t.Errorf("Workloads are not compared")
+	}
+}
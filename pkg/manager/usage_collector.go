@@ -20,25 +20,44 @@ package manager
 import (
 	"time"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
-	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v1"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+	"tkestack.io/volume-decorator/pkg/nodes"
+	"tkestack.io/volume-decorator/pkg/types"
 	"tkestack.io/volume-decorator/pkg/volume"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 )
 
-const usageSyncInterval = time.Minute
+const (
+	usageSyncInterval = time.Minute
+	// maxTimeToFull is the max estimated duration to be recorded, a volume
+	// growing slower than this is treated as not growing.
+	maxTimeToFull = time.Hour * 24 * 365 * 10
+)
 
 // newUsageCollector creates a usageCollector.
 func newUsageCollector(
 	volumeManager volume.Manager,
+	nodeResolver *nodes.Resolver,
+	historyLength int,
+	historyResolution time.Duration,
 	pvcrClient clientset.Interface,
 	pvcLister corelisters.PersistentVolumeClaimLister,
 	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister) *usageCollector {
-	c := &usageCollector{volumeManager: volumeManager}
-	c.controller = newController("usage-collector", c.update, usageSyncInterval, pvcrClient, pvcLister, pvcrLister)
+	c := &usageCollector{
+		volumeManager:     volumeManager,
+		nodeResolver:      nodeResolver,
+		historyLength:     historyLength,
+		historyResolution: historyResolution,
+	}
+	c.controller = newController("usage-collector", storagev2.RuntimeConditionUsageUnavailable, c.update,
+		usageSyncInterval, pvcrClient, pvcLister, pvcrLister)
 	return c
 }
 
@@ -46,23 +65,170 @@ func newUsageCollector(
 type usageCollector struct {
 	*controller
 	volumeManager volume.Manager
+	nodeResolver  *nodes.Resolver
+
+	// historyLength samples will be kept at most, and the interval
+	// between two samples is at least historyResolution.
+	historyLength     int
+	historyResolution time.Duration
 }
 
 // update collects and updates a volume's real usage.
 func (c *usageCollector) update(
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) (*storagev1alpha1.PersistentVolumeClaimRuntime, error) {
-	usage, err := c.volumeManager.Usage(pvcr.Namespace, pvcr.Name)
+	pvcr *storagev2.PersistentVolumeClaimRuntime) (*storagev2.PersistentVolumeClaimRuntime, error) {
+	usage, err := c.volumeManager.Usage(pvcr.Namespace, pvcr.Name, c.volumeNodeNames(pvcr))
 	if err != nil {
 		klog.Errorf("Check real usage for PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
 		return nil, err
 	}
-	if usage == pvcr.Spec.UsageBytes {
+	// Attributes are informational, keep the previous ones instead of
+	// dropping the usage update when the backend can't report them.
+	attributes, err := c.volumeManager.Attributes(pvcr.Namespace, pvcr.Name)
+	if err != nil {
+		klog.Errorf("Get backend attributes for PVC %s/%s failed: %v", pvcr.Namespace, pvcr.Name, err)
+		attributes = pvcr.Status.BackendAttributes
+	}
+	now := metav1.Now()
+	changed := !usageEqual(usage, &pvcr.Status)
+	attributesChanged := !equality.Semantic.DeepEqual(attributes, pvcr.Status.BackendAttributes)
+	if !changed && !attributesChanged &&
+		!c.historyDue(&pvcr.Status, now) && !needRefresh(pvcr.Status.LastUpdated.UsageBytes) {
 		return nil, nil
 	}
-	klog.Infof("Usage bytes of PVC %s/%s changed: %v -> %v", pvcr.Namespace, pvcr.Name, pvcr.Spec.UsageBytes, usage)
+	if changed {
+		klog.Infof("Usage of PVC %s/%s changed: %d/%d bytes, %d/%d inodes -> %d/%d bytes, %d/%d inodes",
+			pvcr.Namespace, pvcr.Name,
+			pvcr.Status.UsageBytes, pvcr.Status.CapacityBytes, pvcr.Status.InodesUsed, pvcr.Status.InodesTotal,
+			usage.UsedBytes, usage.CapacityBytes, usage.InodesUsed, usage.InodesTotal)
+	}
 
 	newPVCR := pvcr.DeepCopy()
-	newPVCR.Spec.UsageBytes = usage
+	newPVCR.Status.UsageBytes = usage.UsedBytes
+	newPVCR.Status.CapacityBytes = usage.CapacityBytes
+	newPVCR.Status.InodesUsed = usage.InodesUsed
+	newPVCR.Status.InodesTotal = usage.InodesTotal
+	newPVCR.Status.UtilizationPercent = utilizationPercent(usage)
+	newPVCR.Status.BackendAttributes = attributes
+	newPVCR.Status.LastUpdated.UsageBytes = &now
+	c.recordUsage(&newPVCR.Status, now)
+	updateFullAt(&newPVCR.Status, now)
 
 	return newPVCR, nil
 }
+
+// historyDue returns true if a new sample should be appended to the usage history.
+func (c *usageCollector) historyDue(status *storagev2.PersistentVolumeClaimRuntimeStatus, now metav1.Time) bool {
+	if c.historyLength <= 0 {
+		return len(status.UsageHistory) > 0
+	}
+	n := len(status.UsageHistory)
+	return n == 0 || now.Sub(status.UsageHistory[n-1].Timestamp.Time) >= c.historyResolution
+}
+
+// recordUsage appends current usage to the history if it is due, and drops the oldest samples.
+func (c *usageCollector) recordUsage(status *storagev2.PersistentVolumeClaimRuntimeStatus, now metav1.Time) {
+	if c.historyLength <= 0 {
+		status.UsageHistory = nil
+		return
+	}
+	if !c.historyDue(status, now) {
+		return
+	}
+	history := append(status.UsageHistory, storagev2.UsageSample{Timestamp: now, UsedBytes: status.UsageBytes})
+	if len(history) > c.historyLength {
+		history = append([]storagev2.UsageSample(nil), history[len(history)-c.historyLength:]...)
+	}
+	status.UsageHistory = history
+}
+
+// updateFullAt estimates the growth rate and when the volume will be full from the usage history.
+func updateFullAt(status *storagev2.PersistentVolumeClaimRuntimeStatus, now metav1.Time) {
+	rate := usageGrowthRate(status.UsageHistory)
+	status.GrowthBytesPerDay = int64(rate * (time.Hour * 24).Seconds())
+	status.FullAt = nil
+
+	if status.CapacityBytes <= 0 {
+		return
+	}
+	free := status.CapacityBytes - status.UsageBytes
+	if free <= 0 {
+		status.FullAt = &now
+		return
+	}
+	if rate <= 0 || float64(free)/rate > maxTimeToFull.Seconds() {
+		return
+	}
+
+	timeToFull := time.Duration(float64(free) / rate * float64(time.Second))
+	fullAt := metav1.NewTime(now.Add(timeToFull))
+	status.FullAt = &fullAt
+}
+
+// usageGrowthRate returns the growth rate in bytes per second, it is the
+// slope of the least squares line fitting the samples.
+func usageGrowthRate(samples []storagev2.UsageSample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	start := samples[0].Timestamp.Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.Timestamp.Sub(start).Seconds()
+		y := float64(s.UsedBytes)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// volumeNodeNames returns names of the nodes which mount the volume, nodes of the
+// consuming pods are included for the backends don't know the mounted nodes.
+func (c *usageCollector) volumeNodeNames(pvcr *storagev2.PersistentVolumeClaimRuntime) []string {
+	nodeNames := make([]string, 0, len(pvcr.Status.MountedNodes))
+	seen := sets.NewString()
+	for _, node := range pvcr.Status.MountedNodes {
+		nodeName := node.NodeName
+		if len(nodeName) == 0 {
+			var err error
+			if nodeName, err = c.nodeResolver.NodeName(node.Address); err != nil {
+				klog.Errorf("Resolve node of address %s failed: %v", node.Address, err)
+			}
+		}
+		if len(nodeName) > 0 && !seen.Has(nodeName) {
+			seen.Insert(nodeName)
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	for _, w := range pvcr.Status.Workloads {
+		for _, pod := range w.Pods {
+			if len(pod.NodeName) > 0 && !seen.Has(pod.NodeName) {
+				seen.Insert(pod.NodeName)
+				nodeNames = append(nodeNames, pod.NodeName)
+			}
+		}
+	}
+	return nodeNames
+}
+
+// usageEqual returns true if usage is the same as the one recorded in status.
+func usageEqual(usage *types.VolumeUsage, status *storagev2.PersistentVolumeClaimRuntimeStatus) bool {
+	return usage.UsedBytes == status.UsageBytes &&
+		usage.CapacityBytes == status.CapacityBytes &&
+		usage.InodesUsed == status.InodesUsed &&
+		usage.InodesTotal == status.InodesTotal
+}
+
+// utilizationPercent returns the percentage of used bytes in capacity, 0 if the capacity is unknown.
+func utilizationPercent(usage *types.VolumeUsage) int32 {
+	if usage.CapacityBytes <= 0 {
+		return 0
+	}
+	return int32(usage.UsedBytes * 100 / usage.CapacityBytes)
+}
@@ -20,13 +20,15 @@ package manager
 import (
 	"time"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
-	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v1"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -34,12 +36,21 @@ import (
 	"k8s.io/klog"
 )
 
+// statusRefreshPeriod is the max age of a runtime field, the field
+// will be rewritten after this period even if it isn't changed.
+const statusRefreshPeriod = time.Minute * 10
+
+// updateFailedReason is the reason of the condition of a controller whose updater failed.
+const updateFailedReason = "UpdateFailed"
+
 type updater func(
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) (*storagev1alpha1.PersistentVolumeClaimRuntime, error)
+	pvcr *storagev2.PersistentVolumeClaimRuntime) (*storagev2.PersistentVolumeClaimRuntime, error)
 
-// newController creates a controller.
+// newController creates a controller. conditionType is the condition set when updater failed,
+// each controller has its own condition so that they never overwrite the errors of each other.
 func newController(
 	name string,
+	conditionType storagev2.PersistentVolumeClaimRuntimeConditionType,
 	updater updater,
 	syncInterval time.Duration,
 	pvcrClient clientset.Interface,
@@ -48,9 +59,10 @@ func newController(
 	queue := workqueue.NewNamedRateLimitingQueue(
 		workqueue.DefaultControllerRateLimiter(), "workload_recycler")
 	return &controller{
-		name:         name,
-		updater:      updater,
-		syncInterval: syncInterval,
+		name:          name,
+		conditionType: conditionType,
+		updater:       updater,
+		syncInterval:  syncInterval,
 
 		pvcLister:  pvcLister,
 		pvcrClient: pvcrClient,
@@ -62,9 +74,10 @@ func newController(
 
 // controller is common framework.
 type controller struct {
-	name         string
-	updater      updater
-	syncInterval time.Duration
+	name          string
+	conditionType storagev2.PersistentVolumeClaimRuntimeConditionType
+	updater       updater
+	syncInterval  time.Duration
 
 	pvcrClient clientset.Interface
 	pvcLister  corelisters.PersistentVolumeClaimLister
@@ -135,35 +148,62 @@ func (c *controller) syncPVC(key string) error {
 		return err
 	}
 
-	newPVCR, err := c.updater(pvcr)
-	if err != nil {
-		return err
+	newPVCR, updateErr := c.updater(pvcr)
+	if updateErr != nil || newPVCR == nil {
+		newPVCR = pvcr.DeepCopy()
 	}
-	if newPVCR == nil {
-		return nil
+	c.setCondition(newPVCR, updateErr)
+	if equality.Semantic.DeepEqual(pvcr.Status, newPVCR.Status) {
+		return updateErr
 	}
 
-	_, err = c.pvcrClient.StorageV1().PersistentVolumeClaimRuntimes(pvcr.Namespace).Update(newPVCR)
+	newPVCR.Status.ObservedGeneration = pvcr.Generation
+	_, err = c.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).UpdateStatus(newPVCR)
 	if err != nil {
 		klog.Errorf("%s Update PVC runtime %s failed: %v", c.name, key, err)
 	}
+	if updateErr != nil {
+		return updateErr
+	}
 	return err
 }
 
+// setCondition sets the condition of the controller according to the result of updater. The
+// BackendError condition shared by the controllers of previous releases is removed.
+func (c *controller) setCondition(pvcr *storagev2.PersistentVolumeClaimRuntime, err error) {
+	pvcr.Status.RemoveCondition(storagev2.RuntimeConditionBackendError)
+	condition := storagev2.PersistentVolumeClaimRuntimeCondition{
+		Type:   c.conditionType,
+		Status: corev1.ConditionFalse,
+	}
+	if err != nil {
+		// The message must be stable for the same error, or the status is rewritten on every sync.
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = updateFailedReason
+		condition.Message = err.Error()
+	}
+	pvcr.Status.SetCondition(condition)
+}
+
 // updatePVCStatus updates a PVC's status.
-func updatePVCStatus(pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) {
-	if len(pvcr.Spec.Workloads) == 0 && len(pvcr.Spec.MountedNodes) == 0 {
-		pvcr.Spec.Statuses = replacePVCStatus(pvcr.Spec.Statuses,
-			storagev1alpha1.ClaimStatusInUse, storagev1alpha1.ClaimStatusAvailable)
+func updatePVCStatus(pvcr *storagev2.PersistentVolumeClaimRuntime) {
+	if len(pvcr.Status.Workloads) == 0 && len(pvcr.Status.MountedNodes) == 0 {
+		pvcr.Status.SetStatuses(replacePVCStatus(pvcr.Status.Statuses,
+			storagev2.ClaimStatusInUse, storagev2.ClaimStatusAvailable))
 	}
 }
 
+// needRefresh returns true if a runtime field updated at lastUpdated should be refreshed.
+func needRefresh(lastUpdated *metav1.Time) bool {
+	return lastUpdated == nil || lastUpdated.Add(statusRefreshPeriod).Before(time.Now())
+}
+
 // replacePVCStatus replace a PVC's status.
 func replacePVCStatus(
-	statuses []storagev1alpha1.PersistentVolumeClaimStatus,
-	oldStatus, newStatus storagev1alpha1.PersistentVolumeClaimStatus) []storagev1alpha1.PersistentVolumeClaimStatus {
+	statuses []storagev2.PersistentVolumeClaimStatus,
+	oldStatus, newStatus storagev2.PersistentVolumeClaimStatus) []storagev2.PersistentVolumeClaimStatus {
 	newStatusExist := false
-	result := make([]storagev1alpha1.PersistentVolumeClaimStatus, 0, len(statuses))
+	result := make([]storagev2.PersistentVolumeClaimStatus, 0, len(statuses))
 	for _, status := range statuses {
 		if status == oldStatus {
 			continue
@@ -179,16 +219,11 @@ func replacePVCStatus(
 	return result
 }
 
-// arrayEqual returns true if two arrays are equal.
-func arrayEqual(a1, a2 []string) bool {
-	if len(a1) != len(a2) {
-		return false
-	}
-	set := sets.NewString(a1...)
-	for _, e := range a2 {
-		if !set.Has(e) {
-			return false
-		}
+// mountedNodeAddresses returns the addresses of mounted nodes.
+func mountedNodeAddresses(nodes []storagev2.MountedNode) []string {
+	addresses := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		addresses = append(addresses, node.Address)
 	}
-	return true
+	return addresses
 }
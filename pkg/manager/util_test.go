@@ -0,0 +1,68 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package manager
+
+import (
+	"fmt"
+	"testing"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSetCondition(t *testing.T) {
+	usage := &controller{conditionType: storagev2.RuntimeConditionUsageUnavailable}
+	nodes := &controller{conditionType: storagev2.RuntimeConditionMountedNodesUnavailable}
+	pvcr := &storagev2.PersistentVolumeClaimRuntime{}
+	pvcr.Status.SetCondition(storagev2.PersistentVolumeClaimRuntimeCondition{
+		Type:   storagev2.RuntimeConditionBackendError,
+		Status: corev1.ConditionTrue,
+		Reason: "UsageUnavailable",
+	})
+
+	usage.setCondition(pvcr, fmt.Errorf("usage of rbd pool rbd is not collected yet"))
+	nodes.setCondition(pvcr, nil)
+	if pvcr.Status.GetCondition(storagev2.RuntimeConditionBackendError) != nil {
+		t.Errorf("Expected the BackendError condition removed")
+	}
+	// The success of a controller doesn't clear the error of another.
+	condition := pvcr.Status.GetCondition(storagev2.RuntimeConditionUsageUnavailable)
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Reason != updateFailedReason ||
+		condition.Message != "usage of rbd pool rbd is not collected yet" {
+		t.Errorf("Unexpected usage condition: %+v", condition)
+	}
+	condition = pvcr.Status.GetCondition(storagev2.RuntimeConditionMountedNodesUnavailable)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		t.Errorf("Unexpected mounted nodes condition: %+v", condition)
+	}
+
+	nodes.setCondition(pvcr, fmt.Errorf("list mds sessions failed"))
+	usage.setCondition(pvcr, nil)
+	condition = pvcr.Status.GetCondition(storagev2.RuntimeConditionUsageUnavailable)
+	if condition.Status != corev1.ConditionFalse || len(condition.Message) > 0 {
+		t.Errorf("Expected the usage condition cleared, got %+v", condition)
+	}
+	condition = pvcr.Status.GetCondition(storagev2.RuntimeConditionMountedNodesUnavailable)
+	if condition.Status != corev1.ConditionTrue {
+		t.Errorf("Expected the mounted nodes condition set, got %+v", condition)
+	}
+	if len(pvcr.Status.Conditions) != 2 {
+		t.Errorf("Unexpected conditions: %+v", pvcr.Status.Conditions)
+	}
+}
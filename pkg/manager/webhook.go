@@ -34,9 +34,9 @@ import (
 
 // newWebhook creates a ValidatingWebhookConfiguration.
 func newWebhook(webhookCfg *config.WebhookConfig) (*v1beta1.ValidatingWebhookConfiguration, error) {
-	caCert, err := ioutil.ReadFile(webhookCfg.CAFile)
+	clientConfig, err := newWebhookClientConfig(webhookCfg, webhookCfg.ValidatingPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate authority from %s: %v", webhookCfg.CAFile, err)
+		return nil, err
 	}
 
 	failurePolicy := v1beta1.Fail
@@ -77,19 +77,7 @@ func newWebhook(webhookCfg *config.WebhookConfig) (*v1beta1.ValidatingWebhookCon
 			},
 		},
 		FailurePolicy: &failurePolicy,
-		ClientConfig: v1beta1.WebhookClientConfig{
-			CABundle: caCert,
-		},
-	}
-	if len(webhookCfg.URL) > 0 {
-		url := "https://" + strings.Trim(webhookCfg.URL, "/") + webhookCfg.ValidatingPath
-		webhook.ClientConfig.URL = &url
-	} else {
-		webhook.ClientConfig.Service = &v1beta1.ServiceReference{
-			Name:      webhookCfg.ServiceName,
-			Namespace: webhookCfg.ServiceNamespace,
-			Path:      &webhookCfg.ValidatingPath,
-		}
+		ClientConfig:  clientConfig,
 	}
 
 	validatingWebhook := &v1beta1.ValidatingWebhookConfiguration{
@@ -102,6 +90,74 @@ func newWebhook(webhookCfg *config.WebhookConfig) (*v1beta1.ValidatingWebhookCon
 	return validatingWebhook, nil
 }
 
+// newClaimWebhook creates a MutatingWebhookConfiguration which records the creator of PVCs, and
+// rejects the updates changing it.
+func newClaimWebhook(webhookCfg *config.WebhookConfig) (*v1beta1.MutatingWebhookConfiguration, error) {
+	clientConfig, err := newWebhookClientConfig(webhookCfg, webhookCfg.MutatingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// PVC creation should not be blocked by the decorator, the creator
+	// is just unknown if the webhook is unavailable.
+	failurePolicy := v1beta1.Ignore
+	webhook := v1beta1.MutatingWebhook{
+		Name: "claim." + webhookCfg.Name + ".storage.tkestack.io",
+		Rules: []v1beta1.RuleWithOperations{
+			{
+				Operations: []v1beta1.OperationType{v1beta1.Create, v1beta1.Update},
+				Rule: v1beta1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"persistentvolumeclaims"},
+				},
+			},
+		},
+		FailurePolicy: &failurePolicy,
+		ClientConfig:  clientConfig,
+	}
+
+	mutatingWebhook := &v1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: webhookCfg.Name,
+		},
+		Webhooks: []v1beta1.MutatingWebhook{webhook},
+	}
+
+	return mutatingWebhook, nil
+}
+
+// newWebhookClientConfig creates the client config of a webhook served at path.
+func newWebhookClientConfig(webhookCfg *config.WebhookConfig, path string) (v1beta1.WebhookClientConfig, error) {
+	caCert, err := ioutil.ReadFile(webhookCfg.CAFile)
+	if err != nil {
+		return v1beta1.WebhookClientConfig{},
+			fmt.Errorf("failed to read certificate authority from %s: %v", webhookCfg.CAFile, err)
+	}
+
+	clientConfig := v1beta1.WebhookClientConfig{CABundle: caCert}
+	if len(webhookCfg.URL) > 0 {
+		url := "https://" + strings.Trim(webhookCfg.URL, "/") + path
+		clientConfig.URL = &url
+	} else {
+		clientConfig.Service = &v1beta1.ServiceReference{
+			Name:      webhookCfg.ServiceName,
+			Namespace: webhookCfg.ServiceNamespace,
+			Path:      &path,
+		}
+	}
+	return clientConfig, nil
+}
+
+// syncClaimWebhook creates or updates the PVC webhook from WebhookConfig.
+func (m *manager) syncClaimWebhook(webhookCfg *config.WebhookConfig) error {
+	mutatingWebhook, err := newClaimWebhook(webhookCfg)
+	if err != nil {
+		return err
+	}
+	return m.syncMutatingWebhook(mutatingWebhook)
+}
+
 // syncWebhook creates or updates a webhook from WebhookConfig.
 func (m *manager) syncWebhook(webhookCfg *config.WebhookConfig) error {
 	validatingWebhook, err := newWebhook(webhookCfg)
@@ -143,7 +199,7 @@ func (m *manager) syncValidatingWebhook(webhook *v1beta1.ValidatingWebhookConfig
 	return nil
 }
 
-// syncValidatingWebhook creates or updates a MutatingWebhookConfiguration.
+// syncMutatingWebhook creates or updates a MutatingWebhookConfiguration.
 func (m *manager) syncMutatingWebhook(webhook *v1beta1.MutatingWebhookConfiguration) error {
 	exist, err := m.k8sClient.AdmissionregistrationV1beta1().
 		MutatingWebhookConfigurations().Get(webhook.Name, metav1.GetOptions{})
@@ -152,9 +208,9 @@ func (m *manager) syncMutatingWebhook(webhook *v1beta1.MutatingWebhookConfigurat
 			_, createErr := m.k8sClient.AdmissionregistrationV1beta1().
 				MutatingWebhookConfigurations().Create(webhook)
 			if createErr != nil {
-				return fmt.Errorf("create mutating webhook %s failed: %v", webhook.Name, err)
+				return fmt.Errorf("create mutating webhook %s failed: %v", webhook.Name, createErr)
 			}
-			klog.Infof("Create mutating webhook %s", webhook.Name)
+			klog.Infof("Created mutating webhook %s", webhook.Name)
 			return nil
 		}
 		return fmt.Errorf("get mutating webhook %s failed: %v", webhook.Name, err)
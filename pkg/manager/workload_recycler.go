@@ -20,12 +20,13 @@ package manager
 import (
 	"time"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
-	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v1"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
 	"tkestack.io/volume-decorator/pkg/workload"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -124,8 +125,8 @@ func (r *workloadRecycler) syncPVCR(key string) error {
 		return err
 	}
 
-	workloads := make([]storagev1alpha1.Workload, 0, len(pvcr.Spec.Workloads))
-	for _, w := range pvcr.Spec.Workloads {
+	workloads := make(map[string]storagev2.Workload, len(pvcr.Status.Workloads))
+	for uid, w := range pvcr.Status.Workloads {
 		exist, existErr := r.workloadManager.Exist(&w.ObjectReference)
 		if existErr != nil {
 			klog.Errorf("Can't determine workload %+v exist or not of PVC %s: %v",
@@ -136,19 +137,22 @@ func (r *workloadRecycler) syncPVCR(key string) error {
 		// If the workload iis just created,, it maybe not exist in the cache.
 		// So we use a delay to make sure the workload is indeed deleted.
 		if exist || w.Timestamp.Time.Add(workloadCheckDelay).After(time.Now()) {
-			workloads = append(workloads, w)
+			workloads[uid] = w
 		}
 	}
 
-	if len(workloads) == len(pvcr.Spec.Workloads) {
+	if len(workloads) == len(pvcr.Status.Workloads) {
 		return nil
 	}
-	klog.Infof("Mounted workloads of PVC %s changed: %v -> %v", key, pvcr.Spec.Workloads, workloads)
+	klog.Infof("Mounted workloads of PVC %s changed: %v -> %v", key, pvcr.Status.Workloads, workloads)
 
+	now := metav1.Now()
 	newPVCR := pvcr.DeepCopy()
-	newPVCR.Spec.Workloads = workloads
+	newPVCR.Status.Workloads = workloads
+	newPVCR.Status.LastUpdated.Workloads = &now
+	newPVCR.Status.ObservedGeneration = pvcr.Generation
 	updatePVCStatus(newPVCR)
-	_, err = r.pvcrClient.StorageV1().PersistentVolumeClaimRuntimes(pvcr.Namespace).Update(newPVCR)
+	_, err = r.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(pvcr.Namespace).UpdateStatus(newPVCR)
 	if err != nil {
 		klog.Errorf("Update workloads of PVC runtime %s failed: %v", key, err)
 	}
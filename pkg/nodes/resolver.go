@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package nodes
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// nodeAddressIndex is the name of the index from node addresses to nodes.
+const nodeAddressIndex = "address"
+
+// NewResolver creates a Resolver, it must be called before the informer started.
+func NewResolver(nodeInformer coreinformers.NodeInformer) (*Resolver, error) {
+	informer := nodeInformer.Informer()
+	if err := informer.AddIndexers(cache.Indexers{nodeAddressIndex: indexNodeAddresses}); err != nil {
+		return nil, fmt.Errorf("add node address indexer failed: %v", err)
+	}
+	return &Resolver{indexer: informer.GetIndexer()}, nil
+}
+
+// Resolver resolves nodes from the addresses reported by storage backends.
+type Resolver struct {
+	indexer cache.Indexer
+}
+
+// NodeName returns the name of the node which owns the address, or an empty
+// string if no node found. InternalIP is preferred when more than one node
+// owns the address, for example, a Hostname equals to other's InternalIP.
+func (r *Resolver) NodeName(address string) (string, error) {
+	objs, err := r.indexer.ByIndex(nodeAddressIndex, address)
+	if err != nil {
+		return "", fmt.Errorf("search node with address %s failed: %v", address, err)
+	}
+
+	nodeName := ""
+	for _, obj := range objs {
+		node := obj.(*corev1.Node)
+		for _, a := range node.Status.Addresses {
+			if a.Address != address {
+				continue
+			}
+			if a.Type == corev1.NodeInternalIP {
+				return node.Name, nil
+			}
+			nodeName = node.Name
+		}
+	}
+
+	return nodeName, nil
+}
+
+// indexNodeAddresses indexes nodes by their addresses.
+func indexNodeAddresses(obj interface{}) ([]string, error) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return nil, nil
+	}
+	addresses := make([]string, 0, len(node.Status.Addresses))
+	for _, a := range node.Status.Addresses {
+		addresses = append(addresses, a.Address)
+	}
+	return addresses, nil
+}
@@ -26,6 +26,8 @@ import (
 	"sync"
 	"time"
 
+	"tkestack.io/volume-decorator/pkg/types"
+
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
@@ -37,8 +39,12 @@ import (
 )
 
 const (
-	kubeletReadonlyPort      = 10255
-	kubeletVolumeUsageMetric = "kubelet_volume_stats_used_bytes"
+	kubeletReadonlyPort = 10255
+
+	kubeletVolumeUsageMetric      = "kubelet_volume_stats_used_bytes"
+	kubeletVolumeCapacityMetric   = "kubelet_volume_stats_capacity_bytes"
+	kubeletVolumeInodesMetric     = "kubelet_volume_stats_inodes"
+	kubeletVolumeInodesUsedMetric = "kubelet_volume_stats_inodes_used"
 
 	syncPeriod   = time.Minute
 	usageTimeout = time.Minute * 5
@@ -64,18 +70,18 @@ func (c *VolumeUsageCollector) Start(stopCh <-chan struct{}) {
 }
 
 // GetUsage returns the real usage of a volume.
-func (c *VolumeUsageCollector) GetUsage(namespace, name string, nodeNames []string) (int64, bool) {
+func (c *VolumeUsageCollector) GetUsage(namespace, name string, nodeNames []string) (*types.VolumeUsage, bool) {
 	for _, nodeName := range nodeNames {
 		value, exist := c.getVolumeUsageFromNode(namespace, name, nodeName)
 		if exist {
 			return value, true
 		}
 	}
-	return 0, false
+	return nil, false
 }
 
 // getVolumeUsageFromNode collects a volume's real usage from kubelet's metric API.
-func (c *VolumeUsageCollector) getVolumeUsageFromNode(namespace, name, nodeName string) (int64, bool) {
+func (c *VolumeUsageCollector) getVolumeUsageFromNode(namespace, name, nodeName string) (*types.VolumeUsage, bool) {
 	key := namespacedVolumeKey(namespace, name)
 	usage, exist := c.usages.Get(nodeName, key)
 	if exist {
@@ -85,7 +91,7 @@ func (c *VolumeUsageCollector) getVolumeUsageFromNode(namespace, name, nodeName
 	values, err := c.syncVolumeUsageFromNode(nodeName, sets.NewString(key))
 	if err != nil {
 		klog.Errorf("Fetch volume usage from node %s failed: %v", nodeName, err)
-		return 0, false
+		return nil, false
 	}
 	c.usages.Update(nodeName, values)
 	usage, exist = values[key]
@@ -112,7 +118,8 @@ func (c *VolumeUsageCollector) syncVolumeUsages() {
 }
 
 // syncVolumeUsageFromNode syncs volumes' usage from kubelet's metric API.
-func (c *VolumeUsageCollector) syncVolumeUsageFromNode(nodeName string, volumes sets.String) (map[string]int64, error) {
+func (c *VolumeUsageCollector) syncVolumeUsageFromNode(
+	nodeName string, volumes sets.String) (map[string]*types.VolumeUsage, error) {
 	address, err := c.getNodeAddress(nodeName)
 	if err != nil {
 		return nil, err
@@ -121,36 +128,58 @@ func (c *VolumeUsageCollector) syncVolumeUsageFromNode(nodeName string, volumes
 		return nil, nil
 	}
 
-	samples, err := getVolumeMetricsFromNode(nodeName, address)
+	ms, err := getVolumeMetricsFromNode(nodeName, address)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make(map[string]int64, volumes.Len())
-	for _, sample := range samples {
-		name, namespace := "", ""
-		for k, v := range sample.Metric {
-			switch k {
-			case "persistentvolumeclaim":
-				name = string(v)
-			case "namespace":
-				namespace = string(v)
+	result := make(map[string]*types.VolumeUsage, volumes.Len())
+	for metricName, samples := range ms {
+		for _, sample := range samples {
+			name, namespace := "", ""
+			for k, v := range sample.Metric {
+				switch k {
+				case "persistentvolumeclaim":
+					name = string(v)
+				case "namespace":
+					namespace = string(v)
+				}
+			}
+			if len(name) == 0 || len(namespace) == 0 {
+				klog.Errorf("Can't get name or namespace from sample: %+v", sample)
+				continue
 			}
-		}
-		if len(name) == 0 || len(namespace) == 0 {
-			klog.Errorf("Can't get name or namespace from sample: %+v", sample)
-			continue
-		}
 
-		key := namespacedVolumeKey(namespace, name)
-		if volumes.Has(key) {
-			result[key] = int64(sample.Value)
+			key := namespacedVolumeKey(namespace, name)
+			if !volumes.Has(key) {
+				continue
+			}
+			usage, exist := result[key]
+			if !exist {
+				usage = &types.VolumeUsage{}
+				result[key] = usage
+			}
+			setUsageField(usage, metricName, int64(sample.Value))
 		}
 	}
 
 	return result, nil
 }
 
+// setUsageField sets the field of usage according to the kubelet metric.
+func setUsageField(usage *types.VolumeUsage, metricName string, value int64) {
+	switch metricName {
+	case kubeletVolumeUsageMetric:
+		usage.UsedBytes = value
+	case kubeletVolumeCapacityMetric:
+		usage.CapacityBytes = value
+	case kubeletVolumeInodesMetric:
+		usage.InodesTotal = value
+	case kubeletVolumeInodesUsedMetric:
+		usage.InodesUsed = value
+	}
+}
+
 // getNodeAddress gets node's IP through k8s API.
 func (c *VolumeUsageCollector) getNodeAddress(nodeName string) (string, error) {
 	node, err := c.nodeLister.Get(nodeName)
@@ -176,8 +205,8 @@ func (c *VolumeUsageCollector) getNodeAddress(nodeName string) (string, error) {
 	return address, nil
 }
 
-// getVolumeMetricsFromNode get metrics from kubelet's API.
-func getVolumeMetricsFromNode(nodeName, address string) (model.Samples, error) {
+// getVolumeMetricsFromNode get volume stats metrics from kubelet's API.
+func getVolumeMetricsFromNode(nodeName, address string) (Metrics, error) {
 	response, err := http.Get(fmt.Sprintf("http://%s:%d/metrics", address, kubeletReadonlyPort))
 	if err != nil {
 		return nil, fmt.Errorf("request to node %s failed: %v", nodeName, err)
@@ -193,30 +222,31 @@ func getVolumeMetricsFromNode(nodeName, address string) (model.Samples, error) {
 		return nil, fmt.Errorf("unexpected status from node %s: %d, %s", nodeName, response.StatusCode, string(data))
 	}
 
-	metrics, err := parseMetrics(string(data))
+	ms, err := ParseMetrics(string(data))
 	if err != nil {
 		return nil, fmt.Errorf("parse metrics from node %s failed: %v", nodeName, err)
 	}
 
-	var usageSamples model.Samples
-	for name, samples := range metrics {
-		if name == kubeletVolumeUsageMetric {
-			usageSamples = samples
-			break
+	volumeMetrics := Metrics{}
+	for _, name := range []string{kubeletVolumeUsageMetric, kubeletVolumeCapacityMetric,
+		kubeletVolumeInodesMetric, kubeletVolumeInodesUsedMetric} {
+		if samples, exist := ms[name]; exist {
+			volumeMetrics[name] = samples
 		}
 	}
-	if len(usageSamples) == 0 {
+	if len(volumeMetrics[kubeletVolumeUsageMetric]) == 0 {
 		return nil, fmt.Errorf("can't find metric %s from node %s", kubeletVolumeUsageMetric, nodeName)
 	}
 
-	return usageSamples, nil
+	return volumeMetrics, nil
 }
 
-type metrics map[string]model.Samples
+// Metrics are samples grouped by metric names.
+type Metrics map[string]model.Samples
 
-// parseMetrics parses kubelet metrics.
-func parseMetrics(data string) (metrics, error) {
-	ms := metrics{}
+// ParseMetrics parses metrics in the Prometheus text format, such as kubelet metrics.
+func ParseMetrics(data string) (Metrics, error) {
+	ms := Metrics{}
 	dec := expfmt.NewDecoder(strings.NewReader(data), expfmt.FmtText)
 	decoder := expfmt.SampleDecoder{
 		Dec:  dec,
@@ -252,7 +282,7 @@ type usages struct {
 
 // usage is a wrapper of volume usage.
 type usage struct {
-	value     int64
+	value     types.VolumeUsage
 	lastQuery time.Time
 }
 
@@ -279,23 +309,24 @@ func (u *usages) Volumes(nodeName string) sets.String {
 }
 
 // Get gets a volume's usage from a specific node.
-func (u *usages) Get(nodeName string, key string) (int64, bool) {
-	u.lock.RLock()
-	defer u.lock.RUnlock()
+func (u *usages) Get(nodeName string, key string) (*types.VolumeUsage, bool) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
 	values, exist := u.usages[nodeName]
 	if !exist {
-		return 0, false
+		return nil, false
 	}
 	usage, exist := values[key]
 	if !exist {
-		return 0, false
+		return nil, false
 	}
 	usage.lastQuery = time.Now()
-	return usage.value, true
+	value := usage.value
+	return &value, true
 }
 
 // Update updates a node's metrics.
-func (u *usages) Update(nodeName string, values map[string]int64) {
+func (u *usages) Update(nodeName string, values map[string]*types.VolumeUsage) {
 	u.lock.Lock()
 	defer u.lock.Unlock()
 
@@ -311,7 +342,7 @@ func (u *usages) Update(nodeName string, values map[string]int64) {
 			us = &usage{lastQuery: time.Now()}
 			usages[key] = us
 		}
-		us.value = value
+		us.value = *value
 	}
 
 	// Clear unused usage.
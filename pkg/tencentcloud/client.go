@@ -0,0 +1,213 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package tencentcloud
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tkestack.io/volume-decorator/pkg/config"
+)
+
+const (
+	cbsService = "cbs"
+	cbsVersion = "2017-03-12"
+	cvmService = "cvm"
+	cvmVersion = "2017-03-12"
+
+	// maxBatchSize is the max count of IDs in one Describe request.
+	maxBatchSize = 100
+
+	requestTimeout = time.Second * 30
+	signAlgorithm  = "TC3-HMAC-SHA256"
+	contentType    = "application/json; charset=utf-8"
+)
+
+// Client is a client of the Tencent Cloud APIs used by the CBS volume.
+type Client interface {
+	// DescribeDisks returns the CBS disks with the IDs, unknown IDs are ignored.
+	DescribeDisks(diskIDs []string) ([]Disk, error)
+	// DescribeInstances returns the CVM instances with the IDs, unknown IDs are ignored.
+	DescribeInstances(instanceIDs []string) ([]Instance, error)
+}
+
+// NewClient creates a Client calling the Tencent Cloud API 3.0.
+func NewClient(cfg *config.TencentCloudConfig) Client {
+	return &client{
+		region:    cfg.Region,
+		secretID:  cfg.SecretID,
+		secretKey: cfg.SecretKey,
+		endpoints: map[string]string{
+			cbsService: cfg.CBSEndpoint,
+			cvmService: cfg.CVMEndpoint,
+		},
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// client is the implementation of Client.
+type client struct {
+	region     string
+	secretID   string
+	secretKey  string
+	endpoints  map[string]string
+	httpClient *http.Client
+}
+
+// DescribeDisks returns the CBS disks with the IDs.
+func (c *client) DescribeDisks(diskIDs []string) ([]Disk, error) {
+	var disks []Disk
+	for len(diskIDs) > 0 {
+		batch := diskIDs
+		if len(batch) > maxBatchSize {
+			batch = batch[:maxBatchSize]
+		}
+		diskIDs = diskIDs[len(batch):]
+
+		response := &describeDisksResponse{}
+		request := map[string]interface{}{"DiskIds": batch, "Limit": len(batch)}
+		if err := c.call(cbsService, cbsVersion, "DescribeDisks", request, response); err != nil {
+			return nil, err
+		}
+		disks = append(disks, response.Response.DiskSet...)
+	}
+	return disks, nil
+}
+
+// DescribeInstances returns the CVM instances with the IDs.
+func (c *client) DescribeInstances(instanceIDs []string) ([]Instance, error) {
+	var instances []Instance
+	for len(instanceIDs) > 0 {
+		batch := instanceIDs
+		if len(batch) > maxBatchSize {
+			batch = batch[:maxBatchSize]
+		}
+		instanceIDs = instanceIDs[len(batch):]
+
+		response := &describeInstancesResponse{}
+		request := map[string]interface{}{"InstanceIds": batch, "Limit": len(batch)}
+		if err := c.call(cvmService, cvmVersion, "DescribeInstances", request, response); err != nil {
+			return nil, err
+		}
+		instances = append(instances, response.Response.InstanceSet...)
+	}
+	return instances, nil
+}
+
+// call invokes an action of a service and decodes the response into result.
+func (c *client) call(service, version, action string, request interface{}, result responseWrapper) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal %s request failed: %v", action, err)
+	}
+	endpoint := c.endpoints[service]
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("parse endpoint %s failed: %v", endpoint, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create %s request failed: %v", action, err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Host", u.Host)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", version)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(timestamp, 10))
+	if len(c.region) > 0 {
+		req.Header.Set("X-TC-Region", c.region)
+	}
+	req.Header.Set("Authorization", c.authorization(service, u.Host, payload, timestamp))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s failed: %v", action, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s response failed: %v", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status of %s: %d, %s", action, resp.StatusCode, string(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("unmarshal %s response failed: %v", action, err)
+	}
+	if apiErr := result.apiError(); apiErr != nil {
+		return fmt.Errorf("%s failed: %v", action, apiErr)
+	}
+	return nil
+}
+
+// authorization generates the TC3-HMAC-SHA256 signature of a request.
+func (c *client) authorization(service, host string, payload []byte, timestamp int64) string {
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+	signedHeaders := "content-type;host"
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:" + contentType + "\nhost:" + host + "\n",
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+	credentialScope := date + "/" + service + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		strconv.FormatInt(timestamp, 10),
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+c.secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, c.secretID, credentialScope, signedHeaders, signature)
+}
+
+// sha256Hex returns the hex encoded sha256 of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
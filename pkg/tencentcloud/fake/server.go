@@ -0,0 +1,163 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"tkestack.io/volume-decorator/pkg/tencentcloud"
+)
+
+// NewServer starts a fake Tencent Cloud API server serving DescribeDisks and
+// DescribeInstances from memory. Point both CBS and CVM endpoints to its URL.
+func NewServer() *Server {
+	s := &Server{
+		disks:     make(map[string]tencentcloud.Disk),
+		instances: make(map[string]tencentcloud.Instance),
+		errors:    make(map[string]tencentcloud.APIError),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Server is a fake Tencent Cloud API server.
+type Server struct {
+	server *httptest.Server
+
+	lock      sync.RWMutex
+	disks     map[string]tencentcloud.Disk
+	instances map[string]tencentcloud.Instance
+	// errors are the API errors returned for the actions instead of their results.
+	errors  map[string]tencentcloud.APIError
+	actions []string
+}
+
+// URL returns the endpoint of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetDisk adds or replaces a disk.
+func (s *Server) SetDisk(disk tencentcloud.Disk) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.disks[disk.DiskID] = disk
+}
+
+// DeleteDisk removes a disk.
+func (s *Server) DeleteDisk(diskID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.disks, diskID)
+}
+
+// SetInstance adds or replaces an instance.
+func (s *Server) SetInstance(instance tencentcloud.Instance) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.instances[instance.InstanceID] = instance
+}
+
+// SetError makes an action fail with an API error, an empty code clears the error.
+func (s *Server) SetError(action, code, message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(code) == 0 {
+		delete(s.errors, action)
+		return
+	}
+	s.errors[action] = tencentcloud.APIError{Code: code, Message: message}
+}
+
+// Actions returns the actions received, in order.
+func (s *Server) Actions() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]string(nil), s.actions...)
+}
+
+// handle serves an API request.
+func (s *Server) handle(w http.ResponseWriter, req *http.Request) {
+	action := req.Header.Get("X-TC-Action")
+	s.lock.Lock()
+	s.actions = append(s.actions, action)
+	s.lock.Unlock()
+
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "TC3-HMAC-SHA256 ") {
+		writeError(w, "AuthFailure.SignatureFailure", "The request is not signed")
+		return
+	}
+
+	request := struct {
+		DiskIds     []string `json:"DiskIds"`
+		InstanceIds []string `json:"InstanceIds"`
+	}{}
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		writeError(w, "InvalidParameter", err.Error())
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if apiErr, exist := s.errors[action]; exist {
+		writeError(w, apiErr.Code, apiErr.Message)
+		return
+	}
+	switch action {
+	case "DescribeDisks":
+		response := tencentcloud.DescribeDisksResponse{}
+		for _, id := range request.DiskIds {
+			if disk, exist := s.disks[id]; exist {
+				response.DiskSet = append(response.DiskSet, disk)
+			}
+		}
+		response.TotalCount = len(response.DiskSet)
+		writeResponse(w, response)
+	case "DescribeInstances":
+		response := tencentcloud.DescribeInstancesResponse{}
+		for _, id := range request.InstanceIds {
+			if instance, exist := s.instances[id]; exist {
+				response.InstanceSet = append(response.InstanceSet, instance)
+			}
+		}
+		response.TotalCount = len(response.InstanceSet)
+		writeResponse(w, response)
+	default:
+		writeError(w, "InvalidAction", "Unsupported action "+action)
+	}
+}
+
+// writeError writes an API error.
+func writeError(w http.ResponseWriter, code, message string) {
+	writeResponse(w, tencentcloud.CommonResponse{Error: &tencentcloud.APIError{Code: code, Message: message}})
+}
+
+// writeResponse writes a response in the envelope of the Tencent Cloud API.
+func writeResponse(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"Response": response})
+}
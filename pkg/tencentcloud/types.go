@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package tencentcloud
+
+import "fmt"
+
+// Disk is a CBS disk.
+type Disk struct {
+	DiskID     string    `json:"DiskId"`
+	DiskType   string    `json:"DiskType"`
+	DiskSize   int64     `json:"DiskSize"`
+	DiskState  string    `json:"DiskState"`
+	Attached   bool      `json:"Attached"`
+	InstanceID string    `json:"InstanceId"`
+	Placement  Placement `json:"Placement"`
+}
+
+// Instance is a CVM instance.
+type Instance struct {
+	InstanceID         string    `json:"InstanceId"`
+	InstanceName       string    `json:"InstanceName"`
+	PrivateIPAddresses []string  `json:"PrivateIpAddresses"`
+	Placement          Placement `json:"Placement"`
+}
+
+// Placement is the location of a disk or an instance.
+type Placement struct {
+	Zone      string `json:"Zone"`
+	ProjectID int64  `json:"ProjectId"`
+}
+
+// APIError is the error returned by the Tencent Cloud API.
+type APIError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// responseWrapper is a response of the Tencent Cloud API.
+type responseWrapper interface {
+	apiError() *APIError
+}
+
+// CommonResponse is the fields shared by all responses.
+type CommonResponse struct {
+	RequestID string    `json:"RequestId"`
+	Error     *APIError `json:"Error,omitempty"`
+}
+
+// DescribeDisksResponse is the response of DescribeDisks.
+type DescribeDisksResponse struct {
+	CommonResponse
+	TotalCount int    `json:"TotalCount"`
+	DiskSet    []Disk `json:"DiskSet"`
+}
+
+// DescribeInstancesResponse is the response of DescribeInstances.
+type DescribeInstancesResponse struct {
+	CommonResponse
+	TotalCount  int        `json:"TotalCount"`
+	InstanceSet []Instance `json:"InstanceSet"`
+}
+
+// describeDisksResponse is the envelope of DescribeDisksResponse.
+type describeDisksResponse struct {
+	Response DescribeDisksResponse `json:"Response"`
+}
+
+// apiError returns the API error of the response.
+func (r *describeDisksResponse) apiError() *APIError { return r.Response.Error }
+
+// describeInstancesResponse is the envelope of DescribeInstancesResponse.
+type describeInstancesResponse struct {
+	Response DescribeInstancesResponse `json:"Response"`
+}
+
+// apiError returns the API error of the response.
+func (r *describeInstancesResponse) apiError() *APIError { return r.Response.Error }
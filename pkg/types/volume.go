@@ -25,6 +25,43 @@ const (
 	CephRBD = "csi-rbd"
 	// CephFS indicates CephFS volume type.
 	CephFS = "csi-cephfs"
+	// CephRBDCSI indicates the RBD driver of current ceph-csi releases, it is served by the CephRBD volume type.
+	CephRBDCSI = "rbd.csi.ceph.com"
+	// CephFSCSI indicates the CephFS driver of current ceph-csi releases, it is served by the CephFS volume type.
+	CephFSCSI = "cephfs.csi.ceph.com"
 	// TencentCBS indicate the CBS volume type in Tencent Cloud.
 	TencentCBS = "csi-tencent-cloud-cbs"
+	// NFS indicates NFS volume type, for in-tree volumes.
+	NFS = "nfs"
+	// NFSCSI indicates the NFS CSI driver, it is served by the NFS volume type.
+	NFSCSI = "nfs.csi.k8s.io"
+	// Local indicates local and hostPath volume types.
+	Local = "local"
 )
+
+// VolumeUsage is the usage of a volume, zero value of a field means it is unknown.
+type VolumeUsage struct {
+	UsedBytes     int64
+	CapacityBytes int64
+	InodesUsed    int64
+	InodesTotal   int64
+}
+
+// Merge fills unknown fields of u from other.
+func (u *VolumeUsage) Merge(other *VolumeUsage) {
+	if other == nil {
+		return
+	}
+	if u.UsedBytes == 0 {
+		u.UsedBytes = other.UsedBytes
+	}
+	if u.CapacityBytes == 0 {
+		u.CapacityBytes = other.CapacityBytes
+	}
+	if u.InodesUsed == 0 {
+		u.InodesUsed = other.InodesUsed
+	}
+	if u.InodesTotal == 0 {
+		u.InodesTotal = other.InodesTotal
+	}
+}
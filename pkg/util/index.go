@@ -0,0 +1,60 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodClaimIndex is the name of the index from PVC keys(namespace/name) to the pods using them.
+const PodClaimIndex = "claim"
+
+// AddPodClaimIndex adds PodClaimIndex to a pod informer if it is not added yet,
+// it must be called before the informer started.
+func AddPodClaimIndex(informer cache.SharedIndexInformer) error {
+	if _, exist := informer.GetIndexer().GetIndexers()[PodClaimIndex]; exist {
+		return nil
+	}
+	if err := informer.AddIndexers(cache.Indexers{PodClaimIndex: indexPodClaims}); err != nil {
+		return fmt.Errorf("add pod claim indexer failed: %v", err)
+	}
+	return nil
+}
+
+// indexPodClaims indexes pods by the keys of PVCs they use.
+func indexPodClaims(obj interface{}) ([]string, error) {
+	return PodClaimKeys(obj), nil
+}
+
+// PodClaimKeys returns the keys of PVCs used by a pod.
+func PodClaimKeys(obj interface{}) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			keys = append(keys, pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return keys
+}
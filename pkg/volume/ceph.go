@@ -18,55 +18,77 @@
 package volume
 
 import (
-	"bufio"
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	"tkestack.io/volume-decorator/pkg/config"
+	"tkestack.io/volume-decorator/pkg/types"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/klog"
 )
 
 const (
-	cephfsVolumesRoot = "/csi-volumes"
+	cephfsUsedBytesAttr   = "ceph.dir.rbytes"
+	cephfsUsedInodesAttr  = "ceph.dir.rentries"
+	cephfsQuotaBytesAttr  = "ceph.quota.max_bytes"
+	cephfsQuotaInodesAttr = "ceph.quota.max_files"
+
+	// mdsSessionMaxAgePeriods is the count of list periods the sessions of an MDS rank are kept without listed.
+	mdsSessionMaxAgePeriods = 3
 )
 
 // newCephRBDVolume creates a volume for CephRBD storage.
-func newCephRBDVolume(config *config.VolumeConfig) volume {
-	return &cephRBDVolume{
-		cephVolume: newCephVolume(config),
+func newCephRBDVolume(config *config.VolumeConfig, secretClient corev1client.SecretsGetter) (volume, error) {
+	cephVolume, err := newCephVolume(config, secretClient)
+	if err != nil {
+		return nil, err
 	}
+	v := &cephRBDVolume{
+		cephVolume:  cephVolume,
+		usagePeriod: config.CephConfig.RBDUsagePeriod,
+	}
+	v.poolUsages = newRBDPoolUsages(v.ExecRBDCommandWithTimeout, config.CephConfig.RBDUsageMaxStaleness)
+	return v, nil
 }
 
 //cephRBDVolume is a wrapper for CephRBD storage.
 type cephRBDVolume struct {
 	cephVolume
+	poolUsages  *rbdPoolUsages
+	usagePeriod time.Duration
 }
 
 // Start starts the volume.
-func (v *cephRBDVolume) Start(stopCh <-chan struct{}) error { return nil }
+func (v *cephRBDVolume) Start(stopCh <-chan struct{}) error {
+	v.stopOn(stopCh)
+	go wait.Until(v.poolUsages.Refresh, v.usagePeriod, stopCh)
+	return nil
+}
 
 // Available returns true if the volume can be mounted by a workload.
 func (v *cephRBDVolume) Available(
-	workload *storagev1alpha1.Workload,
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) error {
+	workload *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
 	return blockVolumeAvailable(workload, pvcr)
 }
 
-// MountedNodes returns the workloads mounted the volume.
-func (v *cephRBDVolume) MountedNodes(pv *corev1.PersistentVolume) ([]string, error) {
+// MountedNodes returns the workloads mounted the volume. Lock holders are
+// ReadWrite, and watchers without the lock are ReadOnly.
+func (v *cephRBDVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
 	rbdInfo := getRBDInfo(pv)
 	watchers, err := v.listRBDWatchers(rbdInfo)
 	if err != nil {
@@ -76,66 +98,46 @@ func (v *cephRBDVolume) MountedNodes(pv *corev1.PersistentVolume) ([]string, err
 	if err != nil {
 		return nil, err
 	}
-	return sets.NewString(append(watchers, lockers...)...).List(), nil
-}
-
-// Usage returns current usage of the volume.
-func (v *cephRBDVolume) Usage(pv *corev1.PersistentVolume) (int64, error) {
-	return v.getUsageByDu(pv)
-}
-
-// Get CephRBD image usage by `rbd du` command.
-func (v *cephRBDVolume) getUsageByDu(pv *corev1.PersistentVolume) (int64, error) {
-	rbdInfo := getRBDInfo(pv)
-	output, err := v.ExecRBDCommandWithTimeout(rbdInfo, longCmdTimeout, "du", rbdInfo.Image)
-	if err != nil {
-		if isRBDImageNotFound(err) {
-			return 0, nil
-		}
-		return 0, fmt.Errorf("diff rbd volume %s failed: %v", pv.Name, err)
-	}
 
-	result := struct {
-		Images []struct {
-			UsedSize int64 `json:"used_size"`
-		} `json:"images"`
-	}{}
-	if err := json.Unmarshal(output, &result); err != nil {
-		return 0, fmt.Errorf("unmarshal layer info of rbd volume %s failed: %v", pv.Name, err)
+	lockerSet := sets.NewString(lockers...)
+	nodes := make([]storagev2.MountedNode, 0, len(watchers)+len(lockers))
+	for _, address := range lockerSet.List() {
+		nodes = append(nodes, storagev2.MountedNode{Address: address, AccessMode: storagev2.MountAccessReadWrite})
 	}
-
-	if len(result.Images) != 1 {
-		return 0, fmt.Errorf("unexpect result count of du %s: %+v", pv.Name, result)
+	for _, address := range sets.NewString(watchers...).Difference(lockerSet).List() {
+		nodes = append(nodes, storagev2.MountedNode{Address: address, AccessMode: storagev2.MountAccessReadOnly})
 	}
-
-	return result.Images[0].UsedSize, nil
+	return nodes, nil
 }
 
-// Get CephRBD image usage by `rbd diff` command.
-func (v *cephRBDVolume) getUsageByDiff(pv *corev1.PersistentVolume) (int64, error) {
-	rbdInfo := getRBDInfo(pv)
-	output, err := v.ExecRBDCommandWithTimeout(rbdInfo, longCmdTimeout, "diff", rbdInfo.Image)
+// Usage returns current usage of the volume from the usage of its pool collected periodically.
+func (v *cephRBDVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	info := getRBDInfo(pv)
+	usage, err := v.poolUsages.Get(info)
 	if err != nil {
-		if isRBDImageNotFound(err) {
-			return 0, nil
-		}
-		return 0, fmt.Errorf("diff rbd volume %s failed: %v", pv.Name, err)
+		return nil, fmt.Errorf("get usage of rbd volume %s failed: %v", pv.Name, err)
 	}
+	return &types.VolumeUsage{UsedBytes: usage.UsedBytes, CapacityBytes: usage.ProvisionedBytes}, nil
+}
 
-	var layers []struct {
-		Offset int   `json:"offset"`
-		Length int64 `json:"length"`
+// Attributes returns the IO rates of the image and the usage of its pool from the MGR
+// prometheus module, nil if it is not enabled or unreachable.
+func (v *cephRBDVolume) Attributes(pv *corev1.PersistentVolume) (map[string]string, error) {
+	info := getRBDInfo(pv)
+	cluster, err := v.clusters.Get(info.ClusterID)
+	if err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal(output, &layers); err != nil {
-		return 0, fmt.Errorf("unmarshal layer info of rbd volume %s failed: %v", pv.Name, err)
+	if len(cluster.MgrPrometheusURL) == 0 {
+		return nil, nil
 	}
-
-	usage := int64(0)
-	for _, layer := range layers {
-		usage += layer.Length
+	attributes, err := v.mgrMetrics.RBDImageAttributes(cluster.MgrPrometheusURL, info.Pool, info.Image)
+	if err != nil {
+		// The CLI knows nothing about the IO rates, just leave them unknown.
+		klog.V(4).Infof("Get mgr metrics of rbd volume %s failed: %v", pv.Name, err)
+		return nil, nil
 	}
-
-	return usage, nil
+	return attributes, nil
 }
 
 // Get the device name(such as `/dev/rbd0`) of a CephRBD image.
@@ -200,10 +202,7 @@ func (v *cephRBDVolume) listRBDLockers(info *rbdInfo) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("status rbd image failed: %v", err)
 	}
-	var lockers []struct {
-		Address string `json:"address"`
-	}
-	err = json.Unmarshal(output, &lockers)
+	lockers, err := parseRBDLockers(output)
 	if err != nil {
 		if isRBDImageNotFound(err) {
 			klog.Warningf("Image %s/%s is deleted, ignore it", info.Pool, info.Image)
@@ -221,180 +220,397 @@ func (v *cephRBDVolume) listRBDLockers(info *rbdInfo) ([]string, error) {
 	return hosts, nil
 }
 
-// getRBDInfo extracts CephRBD information from volume.
+// rbdLocker is a lock of a CephRBD image.
+type rbdLocker struct {
+	Address string `json:"address"`
+}
+
+// parseRBDLockers parses the output of `rbd lock list`, which is an object keyed by the lock
+// id before nautilus, and an array since then.
+func parseRBDLockers(output []byte) ([]rbdLocker, error) {
+	var lockers []rbdLocker
+	if err := json.Unmarshal(output, &lockers); err == nil {
+		return lockers, nil
+	}
+	var lockMap map[string]rbdLocker
+	if err := json.Unmarshal(output, &lockMap); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(lockMap))
+	for id := range lockMap {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		lockers = append(lockers, lockMap[id])
+	}
+	return lockers, nil
+}
+
+// getRBDInfo extracts CephRBD information from volume, both CSI and in-tree volumes are supported.
 func getRBDInfo(pv *corev1.PersistentVolume) *rbdInfo {
+	if source := pv.Spec.RBD; source != nil {
+		return &rbdInfo{
+			Image:     source.RBDImage,
+			Pool:      source.RBDPool,
+			Monitors:  strings.Join(source.CephMonitors, ","),
+			SecretRef: source.SecretRef,
+			User:      source.RadosUser,
+		}
+	}
 	attributes := pv.Spec.CSI.VolumeAttributes
 	info := &rbdInfo{
-		Image:    pv.Name,
-		Pool:     attributes["pool"],
-		Monitors: attributes["monitors"],
+		Image:     rbdImageName(pv.Name, pv.Spec.CSI),
+		Pool:      attributes["pool"],
+		Monitors:  attributes["monitors"],
+		ClusterID: attributes["clusterID"],
+		SecretRef: csiSecretRef(pv.Spec.CSI),
 	}
 	return info
 }
 
+// rbdImageName returns the image of a ceph-csi volume. Current releases record it in the imageName
+// attribute, the image of a static volume is its volume handle, the releases with versioned handles
+// name it after the UUID of the handle, and only the legacy csi-rbd driver names it after the PV.
+func rbdImageName(pvName string, source *corev1.CSIPersistentVolumeSource) string {
+	attributes := source.VolumeAttributes
+	if name := attributes["imageName"]; len(name) > 0 {
+		return name
+	}
+	if attributes["staticVolume"] == "true" {
+		return source.VolumeHandle
+	}
+	if name := csiVolumeName(source.VolumeHandle); len(name) > 0 {
+		return name
+	}
+	return pvName
+}
+
 // rbdInfo is a set of information of CephRBD image.
 type rbdInfo struct {
-	Pool     string
-	Image    string
-	Monitors string
+	Pool  string
+	Image string
+	// Monitors is only set for the volumes provisioned by old ceph-csi releases,
+	// the monitors of ClusterID are used otherwise.
+	Monitors  string
+	ClusterID string
+	// SecretRef is the Secret holding the credentials to access the image, and User
+	// is the user of in-tree Secrets. The credentials of the cluster are used if nil.
+	SecretRef *corev1.SecretReference
+	User      string
 }
 
 // newCephFSVolume creates a volume for CephFS storage.
-func newCephFSVolume(config *config.VolumeConfig) volume {
+func newCephFSVolume(config *config.VolumeConfig, secretClient corev1client.SecretsGetter) (volume, error) {
+	cephVolume, err := newCephVolume(config, secretClient)
+	if err != nil {
+		return nil, err
+	}
 	return &cephFSVolume{
-		cephVolume:           newCephVolume(config),
-		mdsSessions:          newMDSSessions(),
+		cephVolume:           cephVolume,
+		mdsSessions:          newMDSSessions(mdsSessionMaxAgePeriods * config.CephConfig.MdsSessionListPeriod),
+		paths:                newCephFSPaths(),
 		mdsSessionListPeriod: config.CephConfig.MdsSessionListPeriod,
 		cephfsRootPath:       config.CephFSRootPath,
 		cephfsRootMountPath:  config.CephFSRootMountPath,
-	}
+		mountless:            config.CephFSMountless,
+		mounted:              sets.NewString(),
+	}, nil
 }
 
-// cephFSVolume is a wrapper of CephFS volume.
+// cephFSVolume is a wrapper of CephFS volume. The root of each cluster is mounted
+// when the usage of its volumes is first collected, unless in the mountless mode.
 type cephFSVolume struct {
 	cephVolume
 	mdsSessions          *mdsSessions
+	paths                *cephFSPaths
 	mdsSessionListPeriod time.Duration
 	cephfsRootPath       string
 	cephfsRootMountPath  string
+	// mountless is true if the usage is read from the MDS and the subvolume info, the root is never mounted.
+	mountless bool
+
+	// lock protects mounted, which is the set of IDs of the clusters whose root is mounted.
+	lock    sync.Mutex
+	mounted sets.String
 }
 
 // Start starts the volume.
 func (v *cephFSVolume) Start(stopCh <-chan struct{}) error {
-	if err := wait.PollUntil(time.Second*10, v.mountCephRootPath, stopCh); err != nil {
-		return err
-	}
+	v.stopOn(stopCh)
 	go wait.Until(v.listMDSSessions, v.mdsSessionListPeriod, stopCh)
 	return nil
 }
 
 // Available returns true if the volume can be mounted by a workload.
 func (v *cephFSVolume) Available(
-	workload *storagev1alpha1.Workload,
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) error {
+	workload *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
 	return nil
 }
 
-// MountedNodes returns the workloads mounted the volume.
-func (v *cephFSVolume) MountedNodes(pv *corev1.PersistentVolume) ([]string, error) {
-	// Currently CephFS CSI driver doesn't store abs path in the VolumeAttributes for
-	// provisioned volumes. So we need to Splicing the path manually. this is not a good
-	// way as it depends on the internal implement of CephFS CSI driver.
-	path := getCephfsPath(pv)
-	hosts := v.mdsSessions.Get(path)
-	if hosts == nil {
-		klog.V(4).Infof("Cannot find cephfs session for %s", path)
-		return nil, nil
+// MountedNodes returns the workloads mounted the volume. The access mode
+// is unknown since MDS sessions don't tell whether a client is read only.
+func (v *cephFSVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
+	path, err := v.volumePath(pv)
+	if err != nil {
+		return nil, err
 	}
-	return hosts.List(), nil
+	addresses, err := v.mdsSessions.Get(getCephfsClusterID(pv), path)
+	if err != nil {
+		return nil, fmt.Errorf("get mounted nodes of %s failed: %v", pv.Name, err)
+	}
+	nodes := make([]storagev2.MountedNode, 0, addresses.Len())
+	for _, address := range addresses.List() {
+		nodes = append(nodes, storagev2.MountedNode{Address: address, AccessMode: storagev2.MountAccessUnknown})
+	}
+	return nodes, nil
 }
 
 // Usage returns current usage of the volume.
-func (v *cephFSVolume) Usage(pv *corev1.PersistentVolume) (int64, error) {
-	path := filepath.Join(v.cephfsRootMountPath, getCephfsPath(pv))
-	output, err := execCommand("getfattr", []string{"-d", "-m", "ceph.dir.rbytes", path})
+func (v *cephFSVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	// The root is mounted and the MDS are asked with the credentials of the cluster, since the
+	// Secret of a volume may not be allowed to access the whole file system. Only the subvolume
+	// commands are run with the Secret of the volume.
+	cluster, err := v.cluster(getCephfsClusterID(pv), nil, "")
 	if err != nil {
-		return 0, fmt.Errorf("exec getfattr for %s failed: %v", pv.Name, err)
+		return nil, err
 	}
-
-	scanner := bufio.NewScanner(bytes.NewReader(output))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "ceph.dir.rbytes") {
-			continue
-		}
-		usedBytes, err := strconv.ParseInt(strings.Trim(line[strings.Index(line, "=")+1:], "\""), 10, 64)
+	volumePath, err := v.volumePath(pv)
+	if err != nil {
+		return nil, err
+	}
+	if v.mountless {
+		return v.mountlessUsage(pv, cluster, volumePath)
+	}
+	mountPath, err := v.mountRoot(cluster)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(mountPath, volumePath)
+	usage := &types.VolumeUsage{}
+	for name, value := range map[string]*int64{
+		cephfsUsedBytesAttr:   &usage.UsedBytes,
+		cephfsUsedInodesAttr:  &usage.InodesUsed,
+		cephfsQuotaBytesAttr:  &usage.CapacityBytes,
+		cephfsQuotaInodesAttr: &usage.InodesTotal,
+	} {
+		attr, err := v.getCephfsAttr(path, name)
 		if err != nil {
-			return 0, fmt.Errorf("parse usage of %s failed: %v", pv.Name, err)
+			return nil, fmt.Errorf("get %s of %s failed: %v", name, pv.Name, err)
+		}
+		*value = attr
+	}
+	return usage, nil
+}
+
+// Attributes returns the path of the volume, the count of clients mounting it from the MDS sessions, and
+// the pools and clients of the file system from the MGR prometheus module if it is enabled.
+func (v *cephFSVolume) Attributes(pv *corev1.PersistentVolume) (map[string]string, error) {
+	path, err := v.volumePath(pv)
+	if err != nil {
+		return nil, err
+	}
+	clusterID := getCephfsClusterID(pv)
+	attributes := map[string]string{"path": path}
+	if addresses, err := v.mdsSessions.Get(clusterID, path); err != nil {
+		// Leave the clients unknown rather than zero.
+		klog.V(4).Infof("Get clients of cephfs volume %s failed: %v", pv.Name, err)
+	} else {
+		attributes["clients"] = strconv.Itoa(addresses.Len())
+	}
+	cluster, err := v.clusters.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cluster.MgrPrometheusURL) == 0 {
+		return attributes, nil
+	}
+
+	fsName := ""
+	if pv.Spec.CSI != nil {
+		fsName = pv.Spec.CSI.VolumeAttributes["fsName"]
+	}
+	fsAttributes, err := v.mgrMetrics.CephFSAttributes(cluster.MgrPrometheusURL, fsName)
+	if err != nil {
+		klog.V(4).Infof("Get mgr metrics of cephfs volume %s failed: %v", pv.Name, err)
+		return attributes, nil
+	}
+	for key, value := range fsAttributes {
+		attributes[key] = value
+	}
+	return attributes, nil
+}
+
+// getCephfsAttr reads a numeric virtual extended attribute of a CephFS dir.
+// Zero will be returned if the attribute is not set, for example, a dir without quota.
+func (v *cephFSVolume) getCephfsAttr(path, name string) (int64, error) {
+	output, err := v.execCommand("getfattr", []string{"--only-values", "-n", name, path})
+	if err != nil {
+		if strings.Contains(err.Error(), "No such attribute") {
+			return 0, nil
 		}
-		return usedBytes, nil
+		return 0, err
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s failed: %v", string(output), err)
 	}
-	return 0, errors.New("cannot parse getfattr output")
+	return value, nil
+}
+
+// mountRoot mounts the CephFS root path of a cluster if it is not mounted yet, and returns the mount path.
+func (v *cephFSVolume) mountRoot(cluster *cephCluster) (string, error) {
+	// The default cluster keeps the mount path of the releases without the cluster config.
+	mountPath := v.cephfsRootMountPath
+	if len(cluster.ID) > 0 {
+		mountPath = v.cephfsRootMountPath + "-" + url.PathEscape(cluster.ID)
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.mounted.Has(cluster.ID) {
+		return mountPath, nil
+	}
+	if err := v.mountCephRootPath(cluster, mountPath); err != nil {
+		return "", err
+	}
+	v.mounted.Insert(cluster.ID)
+	return mountPath, nil
 }
 
 // mountCephRootPath mounts the CephFS root path to the host so that we can access the CephFS dirs directly.
-func (v *cephFSVolume) mountCephRootPath() (bool, error) {
-	if _, err := os.Stat(v.cephfsRootMountPath); err != nil {
+func (v *cephFSVolume) mountCephRootPath(cluster *cephCluster, mountPath string) error {
+	if _, err := os.Stat(mountPath); err != nil {
 		if os.IsNotExist(err) {
-			klog.Infof("Cephfs root mount point not exist, create it")
-			if createdErr := os.MkdirAll(v.cephfsRootMountPath, 0700); createdErr != nil {
-				klog.Errorf("Create cephfs root mount point %s failed: %v", v.cephfsRootMountPath, createdErr)
-				return false, createdErr
+			klog.Infof("Cephfs root mount point %s not exist, create it", mountPath)
+			if createdErr := os.MkdirAll(mountPath, 0700); createdErr != nil {
+				klog.Errorf("Create cephfs root mount point %s failed: %v", mountPath, createdErr)
+				return createdErr
 			}
 		} else {
-			klog.Errorf("Stat cephfs root mount point %s failed: %v", v.cephfsRootMountPath, err)
-			return false, err
+			klog.Errorf("Stat cephfs root mount point %s failed: %v", mountPath, err)
+			return err
 		}
 	}
 	// Mount point maybe umounted incorrectly, umount manually to eliminate unexpected errors.
-	if _, err := execCommand("umount", []string{v.cephfsRootMountPath}); err != nil {
+	if _, err := v.execCommand("umount", []string{mountPath}); err != nil {
 		if !strings.Contains(err.Error(), "not mounted") &&
 			!strings.Contains(err.Error(), "未挂载") &&
 			!strings.Contains(err.Error(), "mountpoint not found") {
-			klog.Errorf("Umount cephfs root mount dir %s failed: %v",
-				v.cephfsRootMountPath, err)
-			return false, nil
+			klog.Errorf("Umount cephfs root mount dir %s failed: %v", mountPath, err)
+			return err
 		}
 	}
 
-	klog.Info("Mount cephfs root dir")
-	_, err := execCommand("ceph-fuse", v.WithCephConfigArgs(v.cephfsRootMountPath, "-r", v.cephfsRootPath))
+	klog.Infof("Mount cephfs root dir of cluster %q to %s", cluster.ID, mountPath)
+	_, err := v.execCommand("ceph-fuse", cluster.args(mountPath, "-r", v.cephfsRootPath))
 	if err == nil {
 		klog.Info("Mount cephfs root dir succeeded")
-		return true, nil
+		return nil
 	}
 	if strings.Contains(err.Error(), "mountpoint is not empty") {
 		klog.Info("Cephfs root dir is already mounted")
-		return true, nil
+		return nil
 	}
 	klog.Errorf("Mount cephfs root dir failed: %v", err)
-	return false, nil
+	return err
 }
 
-// listMDSSessions list all active mds sessions so that we can know which CephFS dir is mounted on some host.
+// listMDSSessions lists the sessions of all active MDS ranks so that we can know which CephFS dir
+// is mounted on some host. The sessions of a rank failed to list are kept until they age out.
 func (v *cephFSVolume) listMDSSessions() {
-	for _, mds := range v.getAvailableMDS() {
-		sessions, err := v.getMDSSessionList(mds)
+	for _, cluster := range v.clusters.List() {
+		connected, err := v.withCredentials(cluster, nil, "")
 		if err != nil {
+			klog.Errorf("Get credentials of ceph cluster %q failed: %v", cluster.ID, err)
+			v.mdsSessions.Fail(cluster.ID, err)
 			continue
 		}
-		v.mdsSessions.Update(generateSessionSet(sessions))
+		ranks, err := v.getAvailableMDS(connected)
+		if err != nil {
+			v.mdsSessions.Fail(cluster.ID, err)
+			continue
+		}
+		for _, rank := range ranks {
+			sessions, err := v.getMDSSessionList(connected, rank.Name)
+			if err != nil {
+				v.mdsSessions.FailRank(cluster.ID, rank.Key(), err)
+				continue
+			}
+			v.mdsSessions.Update(cluster.ID, rank.Key(), generateSessionSet(sessions))
+		}
+		v.mdsSessions.Retain(cluster.ID, ranks)
 	}
 }
 
-// getAvailableMDS get all active mds servers.
-func (v *cephFSVolume) getAvailableMDS() []string {
-	output, err := execCommand("ceph", v.WithCephConfigArgs("mds", "stat"))
-	if err != nil {
-		klog.Errorf("Get mds stat failed: %v", err)
-		return nil
-	}
+// mdsRank is an active MDS serving a rank of a file system.
+type mdsRank struct {
+	FSName string
+	Rank   int
+	// Name is the name of the MDS daemon, such as "mds.a".
+	Name string
+}
 
-	var mdsList []string
-	scanner := bufio.NewScanner(bytes.NewReader(output))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "up:active") {
-			mdsList = append(mdsList, fetchMDS(line))
-		}
+// Key returns the key of the rank, which doesn't change when another MDS takes over the rank.
+func (r mdsRank) Key() string {
+	return r.FSName + ":" + strconv.Itoa(r.Rank)
+}
+
+// getAvailableMDS gets the active MDS of all ranks of all file systems in a cluster.
+func (v *cephFSVolume) getAvailableMDS(cluster *cephCluster) ([]mdsRank, error) {
+	output, err := v.execCommand("ceph", cluster.args("fs", "dump", "--format", "json"))
+	if err != nil {
+		klog.Errorf("Dump file systems failed: %v", err)
+		return nil, fmt.Errorf("dump file systems failed: %v", err)
 	}
-	if scanner.Err() != nil {
-		klog.Errorf("Parse mds stat failed: %v", err)
+	ranks, err := parseActiveMDS(output)
+	if err != nil {
+		klog.Errorf("Parse file systems failed: %v", err)
+		return nil, err
 	}
 
-	klog.V(4).Infof("Find mds: %v", mdsList)
+	klog.V(4).Infof("Find mds: %v", ranks)
 
-	return mdsList
+	return ranks, nil
 }
 
-// fetchMDS extracts mds address.
-func fetchMDS(info string) string {
-	return "mds." + info[strings.Index(info, "{")+1:strings.Index(info, "=")]
+// parseActiveMDS extracts the active MDS from the output of `ceph fs dump --format json`.
+func parseActiveMDS(output []byte) ([]mdsRank, error) {
+	// Example: {"filesystems":[{"mdsmap":{"fs_name":"cephfs",
+	// "info":{"gid_4135":{"gid":4135,"name":"a","rank":0,"state":"up:active"}}}}]}
+	var dump struct {
+		FileSystems []struct {
+			MDSMap struct {
+				FSName string `json:"fs_name"`
+				Info   map[string]struct {
+					Name  string `json:"name"`
+					Rank  int    `json:"rank"`
+					State string `json:"state"`
+				} `json:"info"`
+			} `json:"mdsmap"`
+		} `json:"filesystems"`
+	}
+	if err := json.Unmarshal(output, &dump); err != nil {
+		return nil, fmt.Errorf("unmarshal file systems failed: %v", err)
+	}
+	var ranks []mdsRank
+	for _, fs := range dump.FileSystems {
+		for _, info := range fs.MDSMap.Info {
+			if info.State != "up:active" {
+				continue
+			}
+			ranks = append(ranks, mdsRank{FSName: fs.MDSMap.FSName, Rank: info.Rank, Name: "mds." + info.Name})
+		}
+	}
+	sort.Slice(ranks, func(i, j int) bool { return ranks[i].Key() < ranks[j].Key() })
+	return ranks, nil
 }
 
-// getMDSSessionList executes ceph command to find active mds.
-func (v *cephFSVolume) getMDSSessionList(mds string) ([]mdsSession, error) {
-	output, err := execCommand("ceph", v.WithCephConfigArgs("tell", mds, "session", "ls"))
+// getMDSSessionList executes ceph command to list the sessions of a mds.
+func (v *cephFSVolume) getMDSSessionList(cluster *cephCluster, mds string) ([]mdsSession, error) {
+	output, err := v.execCommand("ceph", cluster.args("tell", mds, "session", "ls"))
 	if err != nil {
 		klog.Errorf("Exec mds session list failed: %v", err)
 		return nil, err
@@ -407,51 +623,149 @@ func (v *cephFSVolume) getMDSSessionList(mds string) ([]mdsSession, error) {
 	return sessionList, err
 }
 
-// generateSessionSet finds mounted nodes of this dir.
+// generateSessionSet finds mounted nodes of this dir. The client IP is used
+// as the address like CephRBD watchers, and the hostname is used if the IP
+// can't be parsed from the session.
 func generateSessionSet(sessions []mdsSession) map[string]sets.String {
 	sessionSet := make(map[string]sets.String)
 	for _, session := range sessions {
-		if len(session.Metadata.Root) == 0 || len(session.Metadata.Hostname) == 0 {
+		address := parseClientInst(session.Inst)
+		if len(address) == 0 {
+			address = session.Metadata.Hostname
+		}
+		if len(session.Metadata.Root) == 0 || len(address) == 0 {
 			continue
 		}
-		hosts, exist := sessionSet[session.Metadata.Root]
+		addresses, exist := sessionSet[session.Metadata.Root]
 		if !exist {
-			hosts = sets.NewString()
-			sessionSet[session.Metadata.Root] = hosts
+			addresses = sets.NewString()
+			sessionSet[session.Metadata.Root] = addresses
 		}
-		hosts.Insert(session.Metadata.Hostname)
+		addresses.Insert(address)
 	}
 	return sessionSet
 }
 
-// newMDSSessions creates a mdsSessions.
-func newMDSSessions() *mdsSessions {
-	return &mdsSessions{sessions: make(map[string]sets.String)}
+// newMDSSessions creates a mdsSessions, sessions not listed in maxAge are aged out.
+func newMDSSessions(maxAge time.Duration) *mdsSessions {
+	return &mdsSessions{maxAge: maxAge, clusters: make(map[string]*clusterSessions)}
 }
 
-// mdsSessions is a set of mds sessions.
+// mdsSessions is the set of mds sessions of all clusters, it is safe for concurrent access.
 type mdsSessions struct {
-	sync.Mutex
-	// Map cephfs path to mounted hosts.
-	sessions map[string]sets.String
+	maxAge time.Duration
+
+	lock     sync.RWMutex
+	clusters map[string]*clusterSessions
 }
 
-// Update updates all sessions.
-func (s *mdsSessions) Update(sessions map[string]sets.String) {
-	s.Lock()
-	defer s.Unlock()
-	s.sessions = sessions
+// clusterSessions is the sessions of the active MDS ranks of a cluster.
+type clusterSessions struct {
+	// ranks maps the keys of ranks to their sessions.
+	ranks map[string]*rankSessions
+	// err is the error of the last listing of the cluster, nil if the active ranks are known.
+	err error
+}
 
-	klog.V(5).Infof("Update sessions: %v", s.sessions)
+// rankSessions is the sessions of an MDS rank.
+type rankSessions struct {
+	// paths maps cephfs paths to addresses of mounted clients.
+	paths map[string]sets.String
+	// listed is the last time the sessions were listed.
+	listed time.Time
+	// err is the error of the last listing, the sessions listed before are kept.
+	err error
 }
 
-// Get returns mounted nodes of a dir.
-func (s *mdsSessions) Get(path string) sets.String {
-	return s.sessions[path]
+// cluster returns the sessions of a cluster, the lock must be held.
+func (s *mdsSessions) cluster(clusterID string) *clusterSessions {
+	cluster, exist := s.clusters[clusterID]
+	if !exist {
+		cluster = &clusterSessions{ranks: make(map[string]*rankSessions)}
+		s.clusters[clusterID] = cluster
+	}
+	return cluster
+}
+
+// Update replaces the sessions of a rank.
+func (s *mdsSessions) Update(clusterID, rank string, paths map[string]sets.String) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cluster(clusterID).ranks[rank] = &rankSessions{paths: paths, listed: time.Now()}
+
+	klog.V(5).Infof("Update sessions of rank %s of cluster %q: %v", rank, clusterID, paths)
+}
+
+// FailRank records the error of listing the sessions of a rank.
+func (s *mdsSessions) FailRank(clusterID, rank string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cluster := s.cluster(clusterID)
+	sessions, exist := cluster.ranks[rank]
+	if !exist {
+		sessions = &rankSessions{}
+		cluster.ranks[rank] = sessions
+	}
+	sessions.err = err
+}
+
+// Fail records the error of finding the active ranks of a cluster, the sessions are kept.
+func (s *mdsSessions) Fail(clusterID string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cluster(clusterID).err = err
+}
+
+// Retain drops the sessions of the ranks no longer active, such as the ranks of a file system
+// removed or the ranks stopped when max_mds decreased.
+func (s *mdsSessions) Retain(clusterID string, ranks []mdsRank) {
+	active := sets.NewString()
+	for _, rank := range ranks {
+		active.Insert(rank.Key())
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cluster := s.cluster(clusterID)
+	cluster.err = nil
+	for key := range cluster.ranks {
+		if !active.Has(key) {
+			delete(cluster.ranks, key)
+		}
+	}
+}
+
+// Get returns the addresses of the clients mounting a dir in a cluster, merged from all ranks. An
+// error is returned if the sessions of the cluster or any rank are unknown or not listed in maxAge.
+func (s *mdsSessions) Get(clusterID, path string) (sets.String, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	cluster, exist := s.clusters[clusterID]
+	if !exist {
+		return nil, fmt.Errorf("mds sessions of cluster %q are not listed yet", clusterID)
+	}
+
+	addresses := sets.NewString()
+	now := time.Now()
+	for rank, sessions := range cluster.ranks {
+		if now.Sub(sessions.listed) > s.maxAge {
+			if sessions.err != nil {
+				return nil, fmt.Errorf("mds sessions of rank %s are stale: %v", rank, sessions.err)
+			}
+			return nil, fmt.Errorf("mds sessions of rank %s are not listed since %v", rank, sessions.listed)
+		}
+		addresses = addresses.Union(sessions.paths[path])
+	}
+	if len(cluster.ranks) == 0 && cluster.err != nil {
+		return nil, fmt.Errorf("list mds of cluster %q failed: %v", clusterID, cluster.err)
+	}
+	return addresses, nil
 }
 
 // mdsSession is a wrapper of Ceph mds session struct.
 type mdsSession struct {
+	// Inst is the client instance, such as "client.4305 v1:10.0.0.1:0/3251934".
+	Inst     string `json:"inst"`
 	Metadata struct {
 		Root     string `json:"root"`
 		Hostname string `json:"hostname"`
@@ -459,35 +773,81 @@ type mdsSession struct {
 }
 
 // newCephVolume creates a common volume object of Ceph.
-func newCephVolume(config *config.VolumeConfig) cephVolume {
-	return cephVolume{
-		configFile:  config.CephConfig.ConfigFile,
-		keyringFile: config.CephConfig.KeryingFile,
+func newCephVolume(config *config.VolumeConfig, secretClient corev1client.SecretsGetter) (cephVolume, error) {
+	clusters, err := newCephClusters(&config.CephConfig)
+	if err != nil {
+		return cephVolume{}, err
+	}
+	runner, err := newCommandRunner(config)
+	if err != nil {
+		return cephVolume{}, err
 	}
+	return cephVolume{
+		commandRunner: runner,
+		clusters:      clusters,
+		credentials:   newCephCredentials(secretClient),
+		mgrMetrics:    newCephMgrMetrics(),
+	}, nil
 }
 
-// cephVolume is a common framework of Ceph volumes.
+// cephVolume is a common framework of Ceph volumes, all rbd and ceph commands are run by the commandRunner.
 type cephVolume struct {
-	configFile  string
-	keyringFile string
+	*commandRunner
+	clusters    *cephClusters
+	credentials *cephCredentials
+	mgrMetrics  *cephMgrMetrics
 }
 
-// WithCephConfigArgs appends Ceph config related arguments to args.
-func (v *cephVolume) WithCephConfigArgs(args ...string) []string {
-	return append(args, "-c", v.configFile, "--keyring", v.keyringFile)
+// cluster returns the cluster of a clusterID with the credentials in secretRef, or the
+// credentials of the cluster if secretRef is nil. user is the default user of in-tree Secrets.
+func (v *cephVolume) cluster(id string, secretRef *corev1.SecretReference, user string) (*cephCluster, error) {
+	cluster, err := v.clusters.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return v.withCredentials(cluster, secretRef, user)
+}
+
+// withCredentials returns a copy of cluster using the credentials in secretRef, or the Secret of
+// the cluster if secretRef is nil. The cluster is returned as is if neither is set.
+func (v *cephVolume) withCredentials(
+	cluster *cephCluster,
+	secretRef *corev1.SecretReference,
+	user string) (*cephCluster, error) {
+	if secretRef == nil {
+		secretRef = cluster.SecretRef
+	}
+	if secretRef == nil {
+		return cluster, nil
+	}
+	user, keyringFile, err := v.credentials.Get(secretRef, user)
+	if err != nil {
+		return nil, err
+	}
+	newCluster := *cluster
+	newCluster.User, newCluster.KeyringFile = user, keyringFile
+	return &newCluster, nil
 }
 
 // ExecRBDCommand executes a `rbd xxx` command.
 func (v *cephVolume) ExecRBDCommand(info *rbdInfo, args ...string) ([]byte, error) {
-	return execCommand("rbd", v.WithCephConfigArgs(withCephPoolArgs(info, args...)...))
+	return v.ExecRBDCommandWithTimeout(info, defaultCmdTimeout, args...)
 }
 
 // ExecRBDCommandWithTimeout executes a `rbd xxx` command with a custom timeout.
 func (v *cephVolume) ExecRBDCommandWithTimeout(info *rbdInfo, timeout time.Duration, args ...string) ([]byte, error) {
-	return execCmd(timeout, "rbd", v.WithCephConfigArgs(withCephPoolArgs(info, args...)...)...)
+	cluster, err := v.cluster(info.ClusterID, info.SecretRef, info.User)
+	if err != nil {
+		return nil, err
+	}
+	return v.execCmd(timeout, "rbd", cluster.args(withCephPoolArgs(info, args...)...)...)
 }
 
 // withCephPoolArgs appends Ceph poll related arguments to args.
 func withCephPoolArgs(info *rbdInfo, args ...string) []string {
-	return append(args, "--pool", info.Pool, "-m", info.Monitors, "--format", "json")
+	args = append(args, "--pool", info.Pool, "--format", "json")
+	if len(info.Monitors) > 0 {
+		args = append(args, "-m", info.Monitors)
+	}
+	return args
 }
@@ -0,0 +1,218 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tkestack.io/volume-decorator/pkg/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// emptyCephConfigFile is the config file of clusters in the cluster config without one,
+// they are connected with the monitors only.
+const emptyCephConfigFile = "/dev/null"
+
+// cephCluster is a Ceph cluster the volumes are provisioned from.
+type cephCluster struct {
+	// ID is the clusterID of ceph-csi, it is empty for the default cluster.
+	ID          string
+	Monitors    []string
+	ConfigFile  string
+	KeyringFile string
+	User        string
+	// SecretRef is the Secret holding the credentials, it takes precedence over KeyringFile and User.
+	SecretRef *corev1.SecretReference
+	// MgrPrometheusURL is the metrics URL of the MGR prometheus module, empty if not enabled.
+	MgrPrometheusURL string
+	// SubvolumeGroup is the group ceph-csi creates CephFS subvolumes in.
+	SubvolumeGroup string
+}
+
+// args appends arguments connecting to the cluster to args.
+func (c *cephCluster) args(args ...string) []string {
+	args = append(args, "-c", c.ConfigFile, "--keyring", c.KeyringFile)
+	if len(c.Monitors) > 0 {
+		args = append(args, "-m", strings.Join(c.Monitors, ","))
+	}
+	if len(c.User) > 0 {
+		args = append(args, "--id", c.User)
+	}
+	return args
+}
+
+// cephClusterConfig is an entry of the ceph-csi cluster config. Fields other than ClusterID and
+// Monitors are extensions ignored by ceph-csi, so the same ConfigMap can be shared.
+type cephClusterConfig struct {
+	ClusterID   string   `json:"clusterID"`
+	Monitors    []string `json:"monitors"`
+	ConfigFile  string   `json:"configFile,omitempty"`
+	KeyringFile string   `json:"keyringFile,omitempty"`
+	User        string   `json:"user,omitempty"`
+	// SecretRef is the Secret holding the credentials, see cephCredentials for the keys.
+	SecretRef        *corev1.SecretReference `json:"secretRef,omitempty"`
+	MgrPrometheusURL string                  `json:"mgrPrometheusURL,omitempty"`
+	CephFS           struct {
+		SubvolumeGroup string `json:"subvolumeGroup,omitempty"`
+	} `json:"cephFS,omitempty"`
+}
+
+// newCephClusters creates a cephClusters.
+func newCephClusters(config *config.CephConfig) (*cephClusters, error) {
+	defaultCluster := &cephCluster{
+		ConfigFile:       config.ConfigFile,
+		KeyringFile:      config.KeryingFile,
+		MgrPrometheusURL: config.MgrPrometheusURL,
+		SubvolumeGroup:   defaultSubvolumeGroup,
+	}
+	if len(config.Secret) > 0 {
+		namespace, name, err := cache.SplitMetaNamespaceKey(config.Secret)
+		if err != nil || len(namespace) == 0 {
+			return nil, fmt.Errorf("invalid ceph secret %s, namespace/name is required", config.Secret)
+		}
+		defaultCluster.SecretRef = &corev1.SecretReference{Namespace: namespace, Name: name}
+	}
+	return &cephClusters{
+		defaultCluster: defaultCluster,
+		configPath:     config.ClusterConfigFile,
+	}, nil
+}
+
+// cephClusters is the set of known Ceph clusters. The default cluster is configured by flags,
+// and the others are read from the ceph-csi cluster config, which is reloaded once modified.
+type cephClusters struct {
+	defaultCluster *cephCluster
+	configPath     string
+
+	// lock protects the fields below.
+	lock     sync.Mutex
+	modTime  time.Time
+	clusters map[string]*cephCluster
+	// invalid maps the clusterIDs of the entries which can't be used to the reasons.
+	invalid map[string]error
+}
+
+// Get returns the cluster of a clusterID, the default cluster is returned for an empty ID.
+func (c *cephClusters) Get(id string) (*cephCluster, error) {
+	if len(id) == 0 {
+		return c.defaultCluster, nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	if err, invalid := c.invalid[id]; invalid {
+		return nil, err
+	}
+	cluster, exist := c.clusters[id]
+	if !exist {
+		return nil, fmt.Errorf("ceph cluster %s not found in %s", id, c.configPath)
+	}
+	return cluster, nil
+}
+
+// List returns all clusters, the default cluster is included only if its config file exists.
+func (c *cephClusters) List() []*cephCluster {
+	var clusters []*cephCluster
+	if _, err := os.Stat(c.defaultCluster.ConfigFile); err == nil {
+		clusters = append(clusters, c.defaultCluster)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.reload(); err != nil {
+		klog.Errorf("Reload ceph cluster config failed: %v", err)
+	}
+	ids := make([]string, 0, len(c.clusters))
+	for id := range c.clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		clusters = append(clusters, c.clusters[id])
+	}
+	return clusters
+}
+
+// reload reads the cluster config if it is modified since last read, the lock must be held.
+func (c *cephClusters) reload() error {
+	if len(c.configPath) == 0 {
+		return nil
+	}
+	info, err := os.Stat(c.configPath)
+	if err != nil {
+		return fmt.Errorf("stat ceph cluster config %s failed: %v", c.configPath, err)
+	}
+	if c.clusters != nil && info.ModTime().Equal(c.modTime) {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(c.configPath)
+	if err != nil {
+		return fmt.Errorf("read ceph cluster config %s failed: %v", c.configPath, err)
+	}
+	var configs []cephClusterConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return fmt.Errorf("decode ceph cluster config %s failed: %v", c.configPath, err)
+	}
+	clusters := make(map[string]*cephCluster, len(configs))
+	invalid := make(map[string]error)
+	for _, entry := range configs {
+		// The keyring of the default cluster is not allowed to access another cluster.
+		if len(entry.KeyringFile) == 0 && entry.SecretRef == nil {
+			invalid[entry.ClusterID] = fmt.Errorf("ceph cluster %s in %s has neither keyringFile nor secretRef",
+				entry.ClusterID, c.configPath)
+			klog.Errorf("Ignore ceph cluster %s: %v", entry.ClusterID, invalid[entry.ClusterID])
+			continue
+		}
+		cluster := &cephCluster{
+			ID:               entry.ClusterID,
+			Monitors:         entry.Monitors,
+			ConfigFile:       entry.ConfigFile,
+			KeyringFile:      entry.KeyringFile,
+			User:             entry.User,
+			SecretRef:        entry.SecretRef,
+			MgrPrometheusURL: entry.MgrPrometheusURL,
+			SubvolumeGroup:   entry.CephFS.SubvolumeGroup,
+		}
+		if len(cluster.ConfigFile) == 0 {
+			cluster.ConfigFile = emptyCephConfigFile
+		}
+		if len(cluster.SubvolumeGroup) == 0 {
+			cluster.SubvolumeGroup = defaultSubvolumeGroup
+		}
+		clusters[cluster.ID] = cluster
+	}
+
+	klog.Infof("Ceph cluster config %s loaded, %d clusters found", c.configPath, len(clusters))
+	c.clusters = clusters
+	c.invalid = invalid
+	c.modTime = info.ModTime()
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tkestack.io/volume-decorator/pkg/config"
+)
+
+func TestCephClustersCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ceph-clusters")
+	if err != nil {
+		t.Fatalf("Create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(configPath, []byte(`[
+  {"clusterID": "keyring", "monitors": ["10.0.0.1:6789"], "keyringFile": "/etc/ceph-keyring/keyring"},
+  {"clusterID": "secret", "monitors": ["10.0.0.2:6789"], "secretRef": {"namespace": "ceph", "name": "admin"}},
+  {"clusterID": "none", "monitors": ["10.0.0.3:6789"]}
+]`), 0600); err != nil {
+		t.Fatalf("Write cluster config failed: %v", err)
+	}
+	clusters, err := newCephClusters(&config.CephConfig{
+		ConfigFile:        filepath.Join(dir, "ceph.conf"),
+		KeryingFile:       "/etc/ceph/ceph.client.admin.keyring",
+		ClusterConfigFile: configPath,
+	})
+	if err != nil {
+		t.Fatalf("Create clusters failed: %v", err)
+	}
+
+	if cluster, err := clusters.Get("keyring"); err != nil || cluster.KeyringFile != "/etc/ceph-keyring/keyring" {
+		t.Errorf("Unexpected cluster with keyring: %+v, %v", cluster, err)
+	}
+	if cluster, err := clusters.Get("secret"); err != nil || cluster.SecretRef == nil || len(cluster.KeyringFile) > 0 {
+		t.Errorf("Unexpected cluster with secret: %+v, %v", cluster, err)
+	}
+	// The keyring of the default cluster is never used for the others.
+	if _, err := clusters.Get("none"); err == nil || !strings.Contains(err.Error(), "neither keyringFile nor secretRef") {
+		t.Errorf("Expected error of the cluster without credentials, got %v", err)
+	}
+	var ids []string
+	for _, cluster := range clusters.List() {
+		ids = append(ids, cluster.ID)
+	}
+	if strings.Join(ids, ",") != "keyring,secret" {
+		t.Errorf("Unexpected clusters listed: %v", ids)
+	}
+}
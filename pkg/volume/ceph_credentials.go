@@ -0,0 +1,231 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// keyringRefreshInterval is the min interval between two reads of a Secret.
+	keyringRefreshInterval = time.Minute
+	// keyringMaxIdle is how long a keyring is kept after last used, the unused keyrings
+	// are removed at most once every keyringRefreshInterval.
+	keyringMaxIdle = time.Minute * 10
+	// defaultCephUser is the user of in-tree Secrets when the PV doesn't specify one.
+	defaultCephUser = "admin"
+)
+
+// newCephCredentials creates a cephCredentials.
+func newCephCredentials(secretClient corev1client.SecretsGetter) *cephCredentials {
+	return &cephCredentials{
+		secretClient: secretClient,
+		keyrings:     make(map[string]*cephKeyring),
+	}
+}
+
+// cephCredentials writes the keyrings of Ceph users read from Secrets to a private temp dir,
+// and rewrites them once the Secrets changed. The keyrings of deleted Secrets and the ones
+// not used for keyringMaxIdle are removed.
+type cephCredentials struct {
+	secretClient corev1client.SecretsGetter
+
+	// lock protects the fields below and the fields of the keyrings, it is never held
+	// while reading Secrets.
+	lock sync.Mutex
+	// dir is created when the first keyring is written.
+	dir       string
+	keyrings  map[string]*cephKeyring
+	lastPrune time.Time
+}
+
+// cephKeyring is a keyring file written from a Secret for a default user.
+type cephKeyring struct {
+	// fetchLock serializes the reads of the Secret, so that it is read once for concurrent callers.
+	fetchLock sync.Mutex
+	// User and File are empty until the Secret is read.
+	User            string
+	File            string
+	resourceVersion string
+	lastChecked     time.Time
+	lastUsed        time.Time
+}
+
+// Get returns the user and the keyring file of a Secret. Both the ceph-csi keys(userID/userKey
+// or adminID/adminKey) and the in-tree key("key") are supported, defaultUser is used for the latter.
+func (c *cephCredentials) Get(ref *corev1.SecretReference, defaultUser string) (string, string, error) {
+	secretKey := ref.Namespace + "/" + ref.Name
+	// The same in-tree Secret may be used with different users.
+	key := secretKey + "/" + defaultUser
+
+	c.lock.Lock()
+	c.prune()
+	keyring, exist := c.keyrings[key]
+	if !exist {
+		keyring = &cephKeyring{}
+		c.keyrings[key] = keyring
+	}
+	keyring.lastUsed = time.Now()
+	user, file, fresh := keyring.cached()
+	c.lock.Unlock()
+	if fresh {
+		return user, file, nil
+	}
+
+	keyring.fetchLock.Lock()
+	defer keyring.fetchLock.Unlock()
+	c.lock.Lock()
+	user, file, fresh = keyring.cached()
+	rv := keyring.resourceVersion
+	c.lock.Unlock()
+	if fresh {
+		// Read by a concurrent caller.
+		return user, file, nil
+	}
+
+	secret, err := c.secretClient.Secrets(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			c.remove(key, keyring)
+		}
+		return "", "", fmt.Errorf("get ceph secret %s failed: %v", secretKey, err)
+	}
+	if len(file) > 0 && rv == secret.ResourceVersion {
+		keyring.lastChecked = time.Now()
+		return user, file, nil
+	}
+
+	user, userKey, err := parseCephSecret(secret, defaultUser)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid ceph secret %s: %v", secretKey, err)
+	}
+	if len(c.dir) == 0 {
+		if c.dir, err = ioutil.TempDir("", "ceph-keyrings-"); err != nil {
+			return "", "", fmt.Errorf("create keyring dir failed: %v", err)
+		}
+	}
+	// Namespaces and names of Secrets never contain '_'.
+	newFile := filepath.Join(c.dir, ref.Namespace+"_"+ref.Name+"_"+defaultUser+".keyring")
+	if err := writeKeyring(newFile, user, userKey); err != nil {
+		return "", "", err
+	}
+	if len(file) > 0 {
+		klog.Infof("Ceph secret %s changed, keyring %s rotated", secretKey, newFile)
+	}
+	keyring.User, keyring.File = user, newFile
+	keyring.resourceVersion = secret.ResourceVersion
+	keyring.lastChecked = time.Now()
+	if c.keyrings[key] != keyring {
+		// Pruned while reading the Secret.
+		c.keyrings[key] = keyring
+	}
+	return user, newFile, nil
+}
+
+// cached returns the user and the keyring file, fresh is false if the Secret should be read again.
+// The lock of cephCredentials must be held.
+func (k *cephKeyring) cached() (string, string, bool) {
+	fresh := len(k.File) > 0 && time.Since(k.lastChecked) < keyringRefreshInterval
+	return k.User, k.File, fresh
+}
+
+// prune removes the keyrings not used for keyringMaxIdle, the lock must be held.
+func (c *cephCredentials) prune() {
+	if time.Since(c.lastPrune) < keyringRefreshInterval {
+		return
+	}
+	c.lastPrune = time.Now()
+	for key, keyring := range c.keyrings {
+		if time.Since(keyring.lastUsed) > keyringMaxIdle {
+			klog.V(4).Infof("Keyring of ceph secret %s is not used any more, remove it", key)
+			c.remove(key, keyring)
+		}
+	}
+}
+
+// remove deletes a keyring and its file, the lock must be held.
+func (c *cephCredentials) remove(key string, keyring *cephKeyring) {
+	if c.keyrings[key] == keyring {
+		delete(c.keyrings, key)
+	}
+	if len(keyring.File) == 0 {
+		return
+	}
+	if err := os.Remove(keyring.File); err != nil && !os.IsNotExist(err) {
+		klog.Errorf("Remove keyring %s failed: %v", keyring.File, err)
+	}
+	keyring.User, keyring.File, keyring.resourceVersion = "", "", ""
+}
+
+// parseCephSecret returns the user and the key in a Secret.
+func parseCephSecret(secret *corev1.Secret, defaultUser string) (string, string, error) {
+	for _, keys := range [][2]string{{"userID", "userKey"}, {"adminID", "adminKey"}} {
+		if key, exist := secret.Data[keys[1]]; exist {
+			user := string(secret.Data[keys[0]])
+			if len(user) == 0 {
+				return "", "", fmt.Errorf("%s not found", keys[0])
+			}
+			return strings.TrimSpace(user), strings.TrimSpace(string(key)), nil
+		}
+	}
+	if key, exist := secret.Data["key"]; exist {
+		if len(defaultUser) == 0 {
+			defaultUser = defaultCephUser
+		}
+		return defaultUser, strings.TrimSpace(string(key)), nil
+	}
+	return "", "", fmt.Errorf("no ceph key found")
+}
+
+// writeKeyring writes a keyring file which is only readable by the owner. The file is replaced
+// atomically, so commands running with the old keyring are not affected.
+func writeKeyring(file, user, key string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".")
+	if err != nil {
+		return fmt.Errorf("create keyring file failed: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	content := fmt.Sprintf("[client.%s]\n\tkey = %s\n", user, key)
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write keyring file failed: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write keyring file failed: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("rename keyring file to %s failed: %v", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var secretsResource = corev1.SchemeGroupVersion.WithResource("secrets")
+
+// newCephSecret creates an in-tree Secret holding a key.
+func newCephSecret(name, key, resourceVersion string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: name, ResourceVersion: resourceVersion},
+		Data:       map[string][]byte{"key": []byte(key)},
+	}
+}
+
+// readKeyring returns the content of a keyring file.
+func readKeyring(t *testing.T, file string) string {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Read keyring %s failed: %v", file, err)
+	}
+	return string(data)
+}
+
+func TestCephCredentialsUsers(t *testing.T) {
+	client := fake.NewSimpleClientset(newCephSecret("ceph", "AQD1", "1"))
+	credentials := newCephCredentials(client.CoreV1())
+	defer os.RemoveAll(credentials.dir)
+	ref := &corev1.SecretReference{Namespace: "kube-system", Name: "ceph"}
+
+	// The users of different PVs sharing a Secret don't overwrite each other.
+	user1, file1, err := credentials.Get(ref, "kube")
+	if err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	user2, file2, err := credentials.Get(ref, "")
+	if err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	if user1 != "kube" || user2 != defaultCephUser || file1 == file2 {
+		t.Fatalf("Unexpected credentials: %s %s, %s %s", user1, file1, user2, file2)
+	}
+	if content := readKeyring(t, file1); content != "[client.kube]\n\tkey = AQD1\n" {
+		t.Errorf("Unexpected keyring of kube: %q", content)
+	}
+	if content := readKeyring(t, file2); content != "[client.admin]\n\tkey = AQD1\n" {
+		t.Errorf("Unexpected keyring of admin: %q", content)
+	}
+
+	// The Secret is read once a minute.
+	if _, _, err := credentials.Get(ref, "kube"); err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	gets := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "get" {
+			gets++
+		}
+	}
+	if gets != 2 {
+		t.Errorf("Expected 2 reads of the secret, got %d", gets)
+	}
+}
+
+func TestCephCredentialsRotateAndRemove(t *testing.T) {
+	client := fake.NewSimpleClientset(newCephSecret("ceph", "AQD1", "1"))
+	credentials := newCephCredentials(client.CoreV1())
+	defer os.RemoveAll(credentials.dir)
+	ref := &corev1.SecretReference{Namespace: "kube-system", Name: "ceph"}
+
+	_, file, err := credentials.Get(ref, "kube")
+	if err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	if err := client.Tracker().Update(secretsResource, newCephSecret("ceph", "AQD2", "2"), "kube-system"); err != nil {
+		t.Fatalf("Update secret failed: %v", err)
+	}
+	credentials.keyrings["kube-system/ceph/kube"].lastChecked = time.Time{}
+	if _, _, err := credentials.Get(ref, "kube"); err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	if content := readKeyring(t, file); !strings.Contains(content, "AQD2") {
+		t.Errorf("Keyring is not rotated: %q", content)
+	}
+
+	if err := client.Tracker().Delete(secretsResource, "kube-system", "ceph"); err != nil {
+		t.Fatalf("Delete secret failed: %v", err)
+	}
+	credentials.keyrings["kube-system/ceph/kube"].lastChecked = time.Time{}
+	if _, _, err := credentials.Get(ref, "kube"); err == nil {
+		t.Fatalf("Expected error of the deleted secret")
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("Keyring of the deleted secret is not removed: %v", err)
+	}
+	if _, exist := credentials.keyrings["kube-system/ceph/kube"]; exist {
+		t.Errorf("Keyring of the deleted secret is still cached")
+	}
+}
+
+func TestCephCredentialsPrune(t *testing.T) {
+	client := fake.NewSimpleClientset(newCephSecret("ceph", "AQD1", "1"), newCephSecret("ceph2", "AQD2", "1"))
+	credentials := newCephCredentials(client.CoreV1())
+	defer os.RemoveAll(credentials.dir)
+
+	_, file, err := credentials.Get(&corev1.SecretReference{Namespace: "kube-system", Name: "ceph"}, "")
+	if err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	credentials.keyrings["kube-system/ceph/"].lastUsed = time.Now().Add(-keyringMaxIdle - time.Minute)
+	credentials.lastPrune = time.Time{}
+	if _, _, err := credentials.Get(&corev1.SecretReference{Namespace: "kube-system", Name: "ceph2"}, ""); err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("Idle keyring is not removed: %v", err)
+	}
+}
+
+func TestCephCredentialsFetchOutsideLock(t *testing.T) {
+	client := fake.NewSimpleClientset(newCephSecret("ceph", "AQD1", "1"), newCephSecret("ceph2", "AQD2", "1"))
+	credentials := newCephCredentials(client.CoreV1())
+	defer os.RemoveAll(credentials.dir)
+	cached := &corev1.SecretReference{Namespace: "kube-system", Name: "ceph"}
+	if _, _, err := credentials.Get(cached, ""); err != nil {
+		t.Fatalf("Get credentials failed: %v", err)
+	}
+
+	blocked, unblock := make(chan struct{}), make(chan struct{})
+	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		close(blocked)
+		<-unblock
+		return false, nil, nil
+	})
+	done := make(chan error)
+	go func() {
+		_, _, err := credentials.Get(&corev1.SecretReference{Namespace: "kube-system", Name: "ceph2"}, "")
+		done <- err
+	}()
+	<-blocked
+
+	// The cached credentials are returned while another Secret is being read.
+	if _, _, err := credentials.Get(cached, ""); err != nil {
+		t.Errorf("Get cached credentials failed: %v", err)
+	}
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Errorf("Get credentials failed: %v", err)
+	}
+}
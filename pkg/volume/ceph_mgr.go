@@ -0,0 +1,256 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"tkestack.io/volume-decorator/pkg/nodes"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// mgrScrapeInterval is the min interval between two scrapes of a MGR.
+	mgrScrapeInterval = time.Second * 30
+	mgrScrapeTimeout  = time.Second * 10
+
+	mgrRBDReadOpsMetric      = "ceph_rbd_read_ops"
+	mgrRBDWriteOpsMetric     = "ceph_rbd_write_ops"
+	mgrRBDReadBytesMetric    = "ceph_rbd_read_bytes"
+	mgrRBDWriteBytesMetric   = "ceph_rbd_write_bytes"
+	mgrPoolMetadataMetric    = "ceph_pool_metadata"
+	mgrPoolStoredMetric      = "ceph_pool_stored"
+	mgrPoolMaxAvailMetric    = "ceph_pool_max_avail"
+	mgrFSMetadataMetric      = "ceph_fs_metadata"
+	mgrMDSMetadataMetric     = "ceph_mds_metadata"
+	mgrMDSSessionCountMetric = "ceph_mds_sessions_session_count"
+)
+
+// newCephMgrMetrics creates a cephMgrMetrics.
+func newCephMgrMetrics() *cephMgrMetrics {
+	return &cephMgrMetrics{
+		client:    &http.Client{Timeout: mgrScrapeTimeout},
+		endpoints: make(map[string]*mgrEndpoint),
+	}
+}
+
+// cephMgrMetrics scrapes the metrics exported by the prometheus module of Ceph MGRs. The
+// latest two scrapes of each endpoint are kept to calculate the rates of counters.
+type cephMgrMetrics struct {
+	client *http.Client
+
+	// lock protects endpoints and their snapshots, it is never held while scraping.
+	lock      sync.Mutex
+	endpoints map[string]*mgrEndpoint
+}
+
+// mgrEndpoint is the scraped metrics of an endpoint.
+type mgrEndpoint struct {
+	// scrapeLock serializes the scrapes of the endpoint.
+	scrapeLock sync.Mutex
+	snapshots  *mgrSnapshots
+}
+
+// mgrSnapshot is the metrics scraped at a time.
+type mgrSnapshot struct {
+	metrics nodes.Metrics
+	time    time.Time
+}
+
+// mgrSnapshots are the latest two snapshots of an endpoint, previous is nil after the first scrape.
+type mgrSnapshots struct {
+	current  *mgrSnapshot
+	previous *mgrSnapshot
+}
+
+// RBDImageAttributes returns the IO rates of an image and the usage of its pool. The rates
+// are only known if the pool is listed in the rbd_stats_pools option of the MGR.
+func (m *cephMgrMetrics) RBDImageAttributes(url, pool, image string) (map[string]string, error) {
+	snapshots, err := m.get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]string)
+	imageLabels := map[string]string{"pool": pool, "image": image}
+	for attr, metric := range map[string]string{
+		"readIOPS":            mgrRBDReadOpsMetric,
+		"writeIOPS":           mgrRBDWriteOpsMetric,
+		"readBytesPerSecond":  mgrRBDReadBytesMetric,
+		"writeBytesPerSecond": mgrRBDWriteBytesMetric,
+	} {
+		if rate, exist := snapshots.rate(metric, imageLabels); exist {
+			attributes[attr] = strconv.FormatFloat(rate, 'f', 1, 64)
+		}
+	}
+
+	current := snapshots.current.metrics
+	if metadata := findSample(current[mgrPoolMetadataMetric], map[string]string{"name": pool}); metadata != nil {
+		poolLabels := map[string]string{"pool_id": string(metadata.Metric["pool_id"])}
+		if stored := findSample(current[mgrPoolStoredMetric], poolLabels); stored != nil {
+			attributes["poolStoredBytes"] = strconv.FormatInt(int64(stored.Value), 10)
+		}
+		if maxAvail := findSample(current[mgrPoolMaxAvailMetric], poolLabels); maxAvail != nil {
+			attributes["poolMaxAvailBytes"] = strconv.FormatInt(int64(maxAvail.Value), 10)
+		}
+	}
+	return attributes, nil
+}
+
+// CephFSAttributes returns the pools of a file system and the count of clients connected to its
+// MDS daemons. The only file system is used if fsName is empty.
+func (m *cephMgrMetrics) CephFSAttributes(url, fsName string) (map[string]string, error) {
+	snapshots, err := m.get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	current := snapshots.current.metrics
+	var fs *model.Sample
+	if len(fsName) > 0 {
+		fs = findSample(current[mgrFSMetadataMetric], map[string]string{"name": fsName})
+	} else if len(current[mgrFSMetadataMetric]) == 1 {
+		fs = current[mgrFSMetadataMetric][0]
+	}
+	if fs == nil {
+		return nil, nil
+	}
+
+	fsID := string(fs.Metric["fs_id"])
+	clients := int64(0)
+	for _, mds := range current[mgrMDSMetadataMetric] {
+		if string(mds.Metric["fs_id"]) != fsID {
+			continue
+		}
+		daemon := map[string]string{"ceph_daemon": string(mds.Metric["ceph_daemon"])}
+		if sessions := findSample(current[mgrMDSSessionCountMetric], daemon); sessions != nil {
+			clients += int64(sessions.Value)
+		}
+	}
+	return map[string]string{
+		"fsName":       string(fs.Metric["name"]),
+		"dataPools":    string(fs.Metric["data_pools"]),
+		"metadataPool": string(fs.Metric["metadata_pool"]),
+		"fsClients":    strconv.FormatInt(clients, 10),
+	}, nil
+}
+
+// get returns the snapshots of an endpoint, which is scraped if the latest snapshot is
+// older than mgrScrapeInterval. Callers of a stale endpoint wait for one scrape, and the
+// snapshots of the other endpoints can be read meanwhile.
+func (m *cephMgrMetrics) get(url string) (*mgrSnapshots, error) {
+	m.lock.Lock()
+	endpoint, exist := m.endpoints[url]
+	if !exist {
+		endpoint = &mgrEndpoint{}
+		m.endpoints[url] = endpoint
+	}
+	snapshots := endpoint.snapshots
+	m.lock.Unlock()
+	if snapshots.fresh() {
+		return snapshots, nil
+	}
+
+	endpoint.scrapeLock.Lock()
+	defer endpoint.scrapeLock.Unlock()
+	m.lock.Lock()
+	snapshots = endpoint.snapshots
+	m.lock.Unlock()
+	// The endpoint may be scraped by another caller while waiting.
+	if snapshots.fresh() {
+		return snapshots, nil
+	}
+
+	metrics, err := m.scrape(url)
+	if err != nil {
+		return nil, err
+	}
+	newSnapshots := &mgrSnapshots{current: &mgrSnapshot{metrics: metrics, time: time.Now()}}
+	if snapshots != nil {
+		newSnapshots.previous = snapshots.current
+	}
+	m.lock.Lock()
+	endpoint.snapshots = newSnapshots
+	m.lock.Unlock()
+	return newSnapshots, nil
+}
+
+// scrape fetches and parses the metrics of an endpoint.
+func (m *cephMgrMetrics) scrape(url string) (nodes.Metrics, error) {
+	response, err := m.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("request to ceph mgr %s failed: %v", url, err)
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response from ceph mgr %s failed: %v", url, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from ceph mgr %s: %d", url, response.StatusCode)
+	}
+	metrics, err := nodes.ParseMetrics(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse metrics from ceph mgr %s failed: %v", url, err)
+	}
+	return metrics, nil
+}
+
+// fresh returns true if the latest snapshot is scraped within mgrScrapeInterval.
+func (s *mgrSnapshots) fresh() bool {
+	return s != nil && time.Since(s.current.time) < mgrScrapeInterval
+}
+
+// rate returns the per second rate of a counter between the two snapshots.
+func (s *mgrSnapshots) rate(metric string, labels map[string]string) (float64, bool) {
+	if s.previous == nil {
+		return 0, false
+	}
+	current := findSample(s.current.metrics[metric], labels)
+	previous := findSample(s.previous.metrics[metric], labels)
+	if current == nil || previous == nil || current.Value < previous.Value {
+		// The counter is reset if it decreases.
+		return 0, false
+	}
+	seconds := s.current.time.Sub(s.previous.time).Seconds()
+	return float64(current.Value-previous.Value) / seconds, true
+}
+
+// findSample returns the first sample with all the labels, or nil if not found.
+func findSample(samples model.Samples, labels map[string]string) *model.Sample {
+	for _, sample := range samples {
+		matched := true
+		for name, value := range labels {
+			if string(sample.Metric[model.LabelName(name)]) != value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return sample
+		}
+	}
+	return nil
+}
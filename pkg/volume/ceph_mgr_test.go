@@ -0,0 +1,159 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const mgrTestMetrics = `# TYPE ceph_rbd_read_ops counter
+ceph_rbd_read_ops{pool="rbd",namespace="",image="img-1"} %d
+# TYPE ceph_rbd_write_ops counter
+ceph_rbd_write_ops{pool="rbd",namespace="",image="img-1"} 0
+# TYPE ceph_pool_metadata untyped
+ceph_pool_metadata{pool_id="1",name="rbd"} 1
+# TYPE ceph_pool_stored untyped
+ceph_pool_stored{pool_id="1"} 2048
+# TYPE ceph_pool_max_avail untyped
+ceph_pool_max_avail{pool_id="1"} 4096
+# TYPE ceph_fs_metadata untyped
+ceph_fs_metadata{fs_id="1",name="cephfs",data_pools="2",metadata_pool="3"} 1
+# TYPE ceph_mds_metadata untyped
+ceph_mds_metadata{ceph_daemon="mds.a",fs_id="1"} 1
+ceph_mds_metadata{ceph_daemon="mds.b",fs_id="1"} 1
+# TYPE ceph_mds_sessions_session_count gauge
+ceph_mds_sessions_session_count{ceph_daemon="mds.a"} 3
+ceph_mds_sessions_session_count{ceph_daemon="mds.b"} 2
+`
+
+// newMgrServer starts a stand-in of the MGR prometheus module, the read ops of img-1 grow 30 per scrape.
+func newMgrServer() (*httptest.Server, *int32) {
+	scrapes := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(scrapes, 1)
+		fmt.Fprintf(w, mgrTestMetrics, 30*n)
+	}))
+	return server, scrapes
+}
+
+// expire makes the snapshots of an endpoint older than mgrScrapeInterval.
+func (m *cephMgrMetrics) expire(url string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	snapshots := m.endpoints[url].snapshots
+	snapshots.current.time = snapshots.current.time.Add(-mgrScrapeInterval)
+}
+
+func TestCephMgrAttributes(t *testing.T) {
+	server, scrapes := newMgrServer()
+	defer server.Close()
+	m := newCephMgrMetrics()
+
+	attributes, err := m.RBDImageAttributes(server.URL, "rbd", "img-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{"poolStoredBytes": "2048", "poolMaxAvailBytes": "4096"}
+	if !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("Expected %v before the rates are known, got %v", expected, attributes)
+	}
+
+	// The snapshot is reused within the scrape interval.
+	if _, err := m.CephFSAttributes(server.URL, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(scrapes); n != 1 {
+		t.Errorf("Expected 1 scrape, got %d", n)
+	}
+
+	m.expire(server.URL)
+	attributes, err = m.RBDImageAttributes(server.URL, "rbd", "img-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 30 read ops in about 30 seconds.
+	if rate := attributes["readIOPS"]; rate != "1.0" {
+		t.Errorf("Expected read IOPS 1.0, got %s", rate)
+	}
+	if rate := attributes["writeIOPS"]; rate != "0.0" {
+		t.Errorf("Expected write IOPS 0.0, got %s", rate)
+	}
+
+	fsAttributes, err := m.CephFSAttributes(server.URL, "cephfs")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = map[string]string{"fsName": "cephfs", "dataPools": "2", "metadataPool": "3", "fsClients": "5"}
+	if !reflect.DeepEqual(fsAttributes, expected) {
+		t.Errorf("Expected %v, got %v", expected, fsAttributes)
+	}
+}
+
+func TestCephMgrScrapeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "module not enabled", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	m := newCephMgrMetrics()
+
+	if _, err := m.RBDImageAttributes(server.URL, "rbd", "img-1"); err == nil {
+		t.Errorf("Expected an error for unavailable endpoint")
+	}
+	server.Close()
+	if _, err := m.RBDImageAttributes(server.URL, "rbd", "img-1"); err == nil {
+		t.Errorf("Expected an error for closed endpoint")
+	}
+}
+
+func TestCephMgrScrapeOutsideLock(t *testing.T) {
+	scraping, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(scraping)
+		<-release
+		fmt.Fprintf(w, mgrTestMetrics, 0)
+	}))
+	defer slow.Close()
+	defer close(release)
+	fast, _ := newMgrServer()
+	defer fast.Close()
+	m := newCephMgrMetrics()
+
+	go func() {
+		_, _ = m.RBDImageAttributes(slow.URL, "rbd", "img-1")
+	}()
+	<-scraping
+	done := make(chan error)
+	go func() {
+		_, err := m.RBDImageAttributes(fast.URL, "rbd", "img-1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(mgrScrapeTimeout / 2):
+		t.Errorf("Scraping an endpoint is blocked by a slow one")
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"tkestack.io/volume-decorator/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetRBDInfoImage(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		driver     string
+		handle     string
+		attributes map[string]string
+		image      string
+	}{
+		{
+			name:       "image name attribute",
+			driver:     types.CephRBDCSI,
+			handle:     "0001-0002-c1-0000000000000002-7c0e2d4a-b7a3-11ed-9a8b-0242ac110003",
+			attributes: map[string]string{"clusterID": "c1", "imageName": "csi-vol-custom"},
+			image:      "csi-vol-custom",
+		},
+		{
+			name:       "versioned handle",
+			driver:     types.CephRBDCSI,
+			handle:     "0001-0002-c1-0000000000000002-7c0e2d4a-b7a3-11ed-9a8b-0242ac110003",
+			attributes: map[string]string{"clusterID": "c1"},
+			image:      "csi-vol-7c0e2d4a-b7a3-11ed-9a8b-0242ac110003",
+		},
+		{
+			name:       "static volume",
+			driver:     types.CephRBDCSI,
+			handle:     "static-image",
+			attributes: map[string]string{"clusterID": "c1", "staticVolume": "true"},
+			image:      "static-image",
+		},
+		{
+			name:       "legacy csi-rbd",
+			driver:     types.CephRBD,
+			handle:     "csi-rbd-vol-1",
+			attributes: map[string]string{"monitors": "10.0.0.21:6789"},
+			image:      "pv-1",
+		},
+	} {
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:           c.driver,
+					VolumeHandle:     c.handle,
+					VolumeAttributes: c.attributes,
+				}},
+			},
+		}
+		if image := getRBDInfo(pv).Image; image != c.image {
+			t.Errorf("%s: expected image %s, got %s", c.name, c.image, image)
+		}
+	}
+}
+
+// argsExecutor saves the arguments of the commands and outputs nothing.
+type argsExecutor struct {
+	commands [][]string
+}
+
+func (e *argsExecutor) Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	e.commands = append(e.commands, append([]string{name}, args...))
+	return nil, nil
+}
+
+func TestCephFSSubvolumeCredentials(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ceph-csi", Name: "provisioner"},
+		Data:       map[string][]byte{"adminID": []byte("provisioner"), "adminKey": []byte("AQD1")},
+	})
+	credentials := newCephCredentials(client.CoreV1())
+	defer os.RemoveAll(credentials.dir)
+
+	for _, c := range []struct {
+		name      string
+		secretRef *corev1.SecretReference
+		user      string
+	}{
+		{name: "controller expand secret", secretRef: &corev1.SecretReference{Namespace: "ceph-csi", Name: "provisioner"},
+			user: "provisioner"},
+		{name: "no secret"},
+	} {
+		executor := &argsExecutor{}
+		v := &cephFSVolume{cephVolume: cephVolume{
+			commandRunner: &commandRunner{executor: executor, ctx: context.Background()},
+			clusters: &cephClusters{defaultCluster: &cephCluster{
+				ConfigFile:     "/etc/ceph/ceph.conf",
+				KeyringFile:    "/etc/ceph/ceph.client.admin.keyring",
+				SubvolumeGroup: defaultSubvolumeGroup,
+			}},
+			credentials: credentials,
+		}}
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:                    types.CephFSCSI,
+					VolumeHandle:              "0001-0002-c1-0000000000000001-0d2c1c8e-b7a3-11ed-9a8b-0242ac110003",
+					VolumeAttributes:          map[string]string{"fsName": "cephfs"},
+					ControllerExpandSecretRef: c.secretRef,
+				}},
+			},
+		}
+		if err := v.SetQuota(pv, 1024); err != nil {
+			t.Errorf("%s: set quota failed: %v", c.name, err)
+			continue
+		}
+		if len(executor.commands) != 1 {
+			t.Errorf("%s: expected one command, got %v", c.name, executor.commands)
+			continue
+		}
+		args := strings.Join(executor.commands[0], " ")
+		if len(c.user) > 0 {
+			if !strings.Contains(args, "--id "+c.user) || strings.Contains(args, "ceph.client.admin.keyring") {
+				t.Errorf("%s: expected the command run as %s, got %s", c.name, c.user, args)
+			}
+		} else if strings.Contains(args, "--id") || !strings.Contains(args, "ceph.client.admin.keyring") {
+			t.Errorf("%s: expected the command run with the keyring of the cluster, got %s", c.name, args)
+		}
+	}
+}
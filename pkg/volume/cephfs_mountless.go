@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"tkestack.io/volume-decorator/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+// mountlessUsage returns the usage of a CephFS dir without mounting the file system. The
+// recursive stats and quota of the dir are dumped from the MDS caching it, and the usage
+// and quota of a subvolume not cached by any MDS are read from its subvolume info.
+func (v *cephFSVolume) mountlessUsage(
+	pv *corev1.PersistentVolume,
+	cluster *cephCluster,
+	path string) (*types.VolumeUsage, error) {
+	usage, err := v.getMDSDirUsage(cluster, path)
+	if err == nil {
+		return usage, nil
+	}
+	name := getSubvolumeName(pv)
+	if len(name) == 0 {
+		return nil, err
+	}
+	klog.V(4).Infof("Get usage of %s from mds failed, fall back to subvolume info: %v", pv.Name, err)
+	subvolumeCluster, clusterErr := v.subvolumeCluster(pv)
+	if clusterErr != nil {
+		return nil, clusterErr
+	}
+	return v.getSubvolumeUsage(subvolumeCluster, pv.Spec.CSI.VolumeAttributes["fsName"], name)
+}
+
+// getMDSDirUsage asks each active MDS to dump the inode of a dir by `dump tree`. Only the
+// MDS caching the inode knows it, and the recursive stats are propagated lazily as getfattr.
+func (v *cephFSVolume) getMDSDirUsage(cluster *cephCluster, path string) (*types.VolumeUsage, error) {
+	ranks, err := v.getAvailableMDS(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("no active mds found")
+	}
+	var lastErr error
+	for _, rank := range ranks {
+		mds := rank.Name
+		output, err := v.execCommand("ceph", cluster.args("tell", mds, "dump", "tree", path, "0"))
+		if err != nil {
+			lastErr = fmt.Errorf("dump tree %s from %s failed: %v", path, mds, err)
+			continue
+		}
+		var inodes []mdsInode
+		if err := json.Unmarshal(output, &inodes); err != nil {
+			lastErr = fmt.Errorf("unmarshal tree %s from %s failed: %v", path, mds, err)
+			continue
+		}
+		if len(inodes) == 0 {
+			lastErr = fmt.Errorf("%s is not cached by %s", path, mds)
+			continue
+		}
+		// The tree of depth 0 is the dir itself.
+		inode := inodes[0]
+		return &types.VolumeUsage{
+			UsedBytes:     inode.Rstat.RBytes,
+			InodesUsed:    inode.Rstat.RFiles + inode.Rstat.RSubdirs,
+			CapacityBytes: inode.Quota.MaxBytes,
+			InodesTotal:   inode.Quota.MaxFiles,
+		}, nil
+	}
+	return nil, lastErr
+}
+
+// mdsInode is the part of an inode dumped by `dump tree` used to collect usage.
+type mdsInode struct {
+	Rstat struct {
+		RBytes   int64 `json:"rbytes"`
+		RFiles   int64 `json:"rfiles"`
+		RSubdirs int64 `json:"rsubdirs"`
+	} `json:"rstat"`
+	// Quota fields are zero if no quota set.
+	Quota struct {
+		MaxBytes int64 `json:"max_bytes"`
+		MaxFiles int64 `json:"max_files"`
+	} `json:"quota"`
+}
+
+// getSubvolumeUsage reads the used bytes and the quota of a subvolume by `ceph fs subvolume info`,
+// the inodes are unknown.
+func (v *cephFSVolume) getSubvolumeUsage(cluster *cephCluster, fsName, name string) (*types.VolumeUsage, error) {
+	output, err := v.execCommand("ceph", cluster.args(
+		"fs", "subvolume", "info", fsName, name, "--group_name", cluster.SubvolumeGroup, "--format", "json"))
+	if err != nil {
+		return nil, fmt.Errorf("get info of subvolume %s failed: %v", name, err)
+	}
+	// Example: {"bytes_pcent": "undefined", "bytes_quota": "infinite", "bytes_used": 4096, ...}
+	var info struct {
+		BytesUsed  int64           `json:"bytes_used"`
+		BytesQuota json.RawMessage `json:"bytes_quota"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal info of subvolume %s failed: %v", name, err)
+	}
+	usage := &types.VolumeUsage{UsedBytes: info.BytesUsed}
+	// The quota is "infinite" if not set.
+	if quota, err := strconv.ParseInt(string(info.BytesQuota), 10, 64); err == nil {
+		usage.CapacityBytes = quota
+	}
+	return usage, nil
+}
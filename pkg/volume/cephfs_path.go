@@ -0,0 +1,151 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// cephfsVolumesRoot is the dir of the volumes provisioned by ceph-csi v1.0, named after their volume handles.
+	cephfsVolumesRoot = "/csi-volumes"
+	// defaultSubvolumeGroup is the subvolume group of ceph-csi if not configured for the cluster.
+	defaultSubvolumeGroup = "csi"
+	// subvolumeNamePrefix is the prefix ceph-csi names its subvolumes and RBD images with, followed by the
+	// UUID of the volume handle.
+	subvolumeNamePrefix = "csi-vol-"
+	// volumeHandleVersion is the prefix of the versioned volume handles of ceph-csi, the
+	// handles of the releases provisioning subvolumes end with the UUID of the subvolume or the image.
+	volumeHandleVersion = "0001-"
+	uuidLength          = 36
+)
+
+// newCephFSPaths creates a cephFSPaths.
+func newCephFSPaths() *cephFSPaths {
+	return &cephFSPaths{paths: make(map[string]string)}
+}
+
+// cephFSPaths caches the paths of subvolumes, which never change once created.
+type cephFSPaths struct {
+	lock sync.Mutex
+	// paths maps clusterID/fsName/group/subvolume to the path of the subvolume.
+	paths map[string]string
+}
+
+// Get returns the cached path of a subvolume.
+func (p *cephFSPaths) Get(key string) (string, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	path, exist := p.paths[key]
+	return path, exist
+}
+
+// Set caches the path of a subvolume.
+func (p *cephFSPaths) Set(key, path string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.paths[key] = path
+}
+
+// volumePath returns the path of a CephFS volume in the file system. The path of
+// a subvolume is read from the VolumeAttributes if ceph-csi recorded it, or asked from
+// the cluster by `ceph fs subvolume getpath`. Volumes of ceph-csi v1.0, which are not
+// subvolumes, are under cephfsVolumesRoot.
+func (v *cephFSVolume) volumePath(pv *corev1.PersistentVolume) (string, error) {
+	if source := pv.Spec.CephFS; source != nil {
+		// The root of the file system is mounted if no path given.
+		return filepath.Join("/", source.Path), nil
+	}
+
+	attributes := pv.Spec.CSI.VolumeAttributes
+	if attributes["staticVolume"] == "true" {
+		return filepath.Join("/", attributes["rootPath"]), nil
+	}
+	if path := attributes["subvolumePath"]; len(path) > 0 {
+		return filepath.Join("/", path), nil
+	}
+	name := getSubvolumeName(pv)
+	if len(name) == 0 {
+		return filepath.Join(cephfsVolumesRoot, pv.Spec.CSI.VolumeHandle), nil
+	}
+
+	cluster, err := v.subvolumeCluster(pv)
+	if err != nil {
+		return "", err
+	}
+	fsName := attributes["fsName"]
+	key := strings.Join([]string{cluster.ID, fsName, cluster.SubvolumeGroup, name}, "/")
+	if path, exist := v.paths.Get(key); exist {
+		return path, nil
+	}
+	path, err := v.getSubvolumePath(cluster, fsName, name)
+	if err != nil {
+		return "", fmt.Errorf("get path of subvolume %s of %s failed: %v", name, pv.Name, err)
+	}
+	klog.V(4).Infof("Subvolume %s of %s is at %s", name, pv.Name, path)
+	v.paths.Set(key, path)
+	return path, nil
+}
+
+// subvolumeCluster returns the cluster of a CephFS volume with the credentials of the volume
+// returned by cephfsSecretRef, or the ones of the cluster if the volume has no Secret.
+func (v *cephFSVolume) subvolumeCluster(pv *corev1.PersistentVolume) (*cephCluster, error) {
+	secretRef, user := cephfsSecretRef(pv)
+	return v.cluster(getCephfsClusterID(pv), secretRef, user)
+}
+
+// getSubvolumePath asks the cluster for the path of a subvolume in its subvolume group.
+func (v *cephFSVolume) getSubvolumePath(cluster *cephCluster, fsName, name string) (string, error) {
+	output, err := v.execCommand("ceph", cluster.args(
+		"fs", "subvolume", "getpath", fsName, name, "--group_name", cluster.SubvolumeGroup))
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("unexpected subvolume path: %s", path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// getSubvolumeName returns the name of the subvolume of a CephFS volume, or an empty
+// string if the volume is not a subvolume provisioned by ceph-csi.
+func getSubvolumeName(pv *corev1.PersistentVolume) string {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.VolumeAttributes["staticVolume"] == "true" {
+		return ""
+	}
+	if name := pv.Spec.CSI.VolumeAttributes["subvolumeName"]; len(name) > 0 {
+		return name
+	}
+	return csiVolumeName(pv.Spec.CSI.VolumeHandle)
+}
+
+// csiVolumeName returns the name of the subvolume or the RBD image ceph-csi created for a
+// versioned volume handle, or an empty string for the handles of ceph-csi v1.0.
+func csiVolumeName(volumeHandle string) string {
+	if !strings.HasPrefix(volumeHandle, volumeHandleVersion) || len(volumeHandle) < len(volumeHandleVersion)+uuidLength {
+		return ""
+	}
+	return subvolumeNamePrefix + volumeHandle[len(volumeHandle)-uuidLength:]
+}
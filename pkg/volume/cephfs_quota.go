@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Quota returns the quota of the volume in bytes, it is read the same way as the usage. Only
+// the quota of the volumes provisioned by ceph-csi is managed, in-tree and static volumes may
+// be dirs shared with others.
+func (v *cephFSVolume) Quota(pv *corev1.PersistentVolume) (*int64, error) {
+	if !cephfsProvisioned(pv) {
+		return nil, nil
+	}
+	cluster, err := v.cluster(getCephfsClusterID(pv), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	volumePath, err := v.volumePath(pv)
+	if err != nil {
+		return nil, err
+	}
+	if v.mountless {
+		usage, err := v.mountlessUsage(pv, cluster, volumePath)
+		if err != nil {
+			return nil, err
+		}
+		return &usage.CapacityBytes, nil
+	}
+	mountPath, err := v.mountRoot(cluster)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := v.getCephfsAttr(filepath.Join(mountPath, volumePath), cephfsQuotaBytesAttr)
+	if err != nil {
+		return nil, fmt.Errorf("get %s of %s failed: %v", cephfsQuotaBytesAttr, pv.Name, err)
+	}
+	return &quota, nil
+}
+
+// SetQuota sets the quota of the volume in bytes. Subvolumes are resized by `ceph fs subvolume resize`
+// like ceph-csi does, which refuses to shrink them, and the quota attribute of the dirs of ceph-csi v1.0 is set on the mounted root.
+func (v *cephFSVolume) SetQuota(pv *corev1.PersistentVolume, bytes int64) error {
+	if !cephfsProvisioned(pv) {
+		return fmt.Errorf("quota of %s is not managed since it is not provisioned by ceph-csi", pv.Name)
+	}
+	size := strconv.FormatInt(bytes, 10)
+	if name := getSubvolumeName(pv); len(name) > 0 {
+		cluster, err := v.subvolumeCluster(pv)
+		if err != nil {
+			return err
+		}
+		_, err = v.execCommand("ceph", cluster.args("fs", "subvolume", "resize",
+			pv.Spec.CSI.VolumeAttributes["fsName"], name, size, "--group_name", cluster.SubvolumeGroup, "--no_shrink"))
+		if err != nil {
+			return fmt.Errorf("resize subvolume %s of %s failed: %v", name, pv.Name, err)
+		}
+		return nil
+	}
+
+	if v.mountless {
+		return fmt.Errorf("quota of %s can't be set without mounting the root since it is not a subvolume", pv.Name)
+	}
+	volumePath, err := v.volumePath(pv)
+	if err != nil {
+		return err
+	}
+	// The root is mounted with the credentials of the cluster, see Usage.
+	cluster, err := v.cluster(getCephfsClusterID(pv), nil, "")
+	if err != nil {
+		return err
+	}
+	mountPath, err := v.mountRoot(cluster)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(mountPath, volumePath)
+	if _, err := v.execCommand("setfattr", []string{"-n", cephfsQuotaBytesAttr, "-v", size, path}); err != nil {
+		return fmt.Errorf("set %s of %s failed: %v", cephfsQuotaBytesAttr, pv.Name, err)
+	}
+	return nil
+}
+
+// cephfsProvisioned returns true if a CephFS volume is provisioned by ceph-csi.
+func cephfsProvisioned(pv *corev1.PersistentVolume) bool {
+	return pv.Spec.CSI != nil && pv.Spec.CSI.VolumeAttributes["staticVolume"] != "true"
+}
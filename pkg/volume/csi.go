@@ -0,0 +1,140 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"sort"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/types"
+	"tkestack.io/volume-decorator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	storageinformers "k8s.io/client-go/informers/storage/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// attachmentVolumeIndex is the name of the index from PVs to their VolumeAttachments.
+const attachmentVolumeIndex = "volume"
+
+// newGenericCSIVolume creates a volume for any CSI driver, it must be called before the informers started.
+func newGenericCSIVolume(
+	attachmentInformer storageinformers.VolumeAttachmentInformer,
+	podInformer coreinformers.PodInformer,
+	nodeLister corelisters.NodeLister) (volume, error) {
+	informer := attachmentInformer.Informer()
+	if _, exist := informer.GetIndexer().GetIndexers()[attachmentVolumeIndex]; !exist {
+		if err := informer.AddIndexers(cache.Indexers{attachmentVolumeIndex: indexAttachmentVolume}); err != nil {
+			return nil, fmt.Errorf("add volume attachment indexer failed: %v", err)
+		}
+	}
+	pods := podInformer.Informer()
+	if err := util.AddPodClaimIndex(pods); err != nil {
+		return nil, err
+	}
+	return &genericCSIVolume{
+		attachmentIndexer: informer.GetIndexer(),
+		attachmentSynced:  informer.HasSynced,
+		podIndexer:        pods.GetIndexer(),
+		podSynced:         pods.HasSynced,
+		nodeLister:        nodeLister,
+	}, nil
+}
+
+// genericCSIVolume is a volume of CSI drivers, or in-tree volumes, without an enabled backend. Mounted
+// nodes come from VolumeAttachments, or the scheduled pods using the volume for drivers which don't
+// require attaching, and usage comes from the kubelet volume stats.
+type genericCSIVolume struct {
+	attachmentIndexer cache.Indexer
+	attachmentSynced  cache.InformerSynced
+	podIndexer        cache.Indexer
+	podSynced         cache.InformerSynced
+	nodeLister        corelisters.NodeLister
+}
+
+// Start starts the volume.
+func (v *genericCSIVolume) Start(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, v.attachmentSynced, v.podSynced) {
+		return fmt.Errorf("wait for volume attachment and pod cache synced timeout")
+	}
+	return nil
+}
+
+// Available returns true if the volume can be mounted by a workload.
+func (v *genericCSIVolume) Available(
+	w *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
+	return accessModesAvailable(w, pv, pvcr)
+}
+
+// MountedNodes returns the nodes the volume attached to. The nodes of the scheduled pods using
+// the volume are returned if it has no VolumeAttachment, e.g. the driver doesn't require attaching.
+func (v *genericCSIVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
+	objs, err := v.attachmentIndexer.ByIndex(attachmentVolumeIndex, pv.Name)
+	if err != nil {
+		return nil, fmt.Errorf("search volume attachments of %s failed: %v", pv.Name, err)
+	}
+
+	readOnly := pv.Spec.CSI != nil && pv.Spec.CSI.ReadOnly
+	if len(objs) == 0 {
+		return podMountedNodes(v.podIndexer, v.nodeLister, pv, readOnly)
+	}
+	accessMode := storagev2.MountAccessUnknown
+	if readOnly {
+		accessMode = storagev2.MountAccessReadOnly
+	}
+	nodes := make([]storagev2.MountedNode, 0, len(objs))
+	for _, obj := range objs {
+		attachment := obj.(*storagev1.VolumeAttachment)
+		if !attachment.Status.Attached {
+			continue
+		}
+		address, err := nodeAddress(v.nodeLister, attachment.Spec.NodeName)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, storagev2.MountedNode{
+			NodeName:   attachment.Spec.NodeName,
+			Address:    address,
+			AccessMode: accessMode,
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Address < nodes[j].Address })
+
+	return nodes, nil
+}
+
+// Usage returns current usage of the volume. The CSI driver is not asked,
+// all fields are left to the kubelet volume stats.
+func (v *genericCSIVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	return &types.VolumeUsage{}, nil
+}
+
+// indexAttachmentVolume indexes VolumeAttachments by the names of their PVs.
+func indexAttachmentVolume(obj interface{}) ([]string, error) {
+	attachment, ok := obj.(*storagev1.VolumeAttachment)
+	if !ok || attachment.Spec.Source.PersistentVolumeName == nil {
+		return nil, nil
+	}
+	return []string{*attachment.Spec.Source.PersistentVolumeName}, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"reflect"
+	"testing"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newCSITestPod creates a pod on a node using the PVC data.
+func newCSITestPod(name, nodeName string, readOnly bool) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "data",
+					ReadOnly:  readOnly,
+				}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestGenericCSIMountedNodes(t *testing.T) {
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	attachments := factory.Storage().V1().VolumeAttachments()
+	pods := factory.Core().V1().Pods()
+	nodes := factory.Core().V1().Nodes()
+	v, err := newGenericCSIVolume(attachments, pods, nodes.Lister())
+	if err != nil {
+		t.Fatalf("Create volume failed: %v", err)
+	}
+	nodes.Informer().GetIndexer().Add(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	})
+	pods.Informer().GetIndexer().Add(newCSITestPod("reader", "node-1", true))
+	pods.Informer().GetIndexer().Add(newCSITestPod("writer", "node-2", false))
+	pods.Informer().GetIndexer().Add(newCSITestPod("pending", "", false))
+
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "data"},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "nfs.csi.k8s.io", VolumeHandle: "vol-1"},
+			},
+		},
+	}
+
+	// Drivers without an attach step create no VolumeAttachment.
+	mountedNodes, err := v.MountedNodes(pv)
+	if err != nil {
+		t.Fatalf("Get mounted nodes failed: %v", err)
+	}
+	expected := []storagev2.MountedNode{
+		{NodeName: "node-1", Address: "10.0.0.1", AccessMode: storagev2.MountAccessReadOnly},
+		{NodeName: "node-2", Address: "node-2", AccessMode: storagev2.MountAccessReadWrite},
+	}
+	if !reflect.DeepEqual(mountedNodes, expected) {
+		t.Errorf("Expected nodes of pods %v, got %v", expected, mountedNodes)
+	}
+
+	pvName := pv.Name
+	attachments.Informer().GetIndexer().Add(&storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "attachment-1"},
+		Spec: storagev1.VolumeAttachmentSpec{
+			NodeName: "node-1",
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+		},
+		Status: storagev1.VolumeAttachmentStatus{Attached: true},
+	})
+	mountedNodes, err = v.MountedNodes(pv)
+	if err != nil {
+		t.Fatalf("Get mounted nodes failed: %v", err)
+	}
+	expected = []storagev2.MountedNode{
+		{NodeName: "node-1", Address: "10.0.0.1", AccessMode: storagev2.MountAccessUnknown},
+	}
+	if !reflect.DeepEqual(mountedNodes, expected) {
+		t.Errorf("Expected attached nodes %v, got %v", expected, mountedNodes)
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/types"
+	"tkestack.io/volume-decorator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// nodeNameField is the only field supported by the MatchFields of node selector terms.
+const nodeNameField = "metadata.name"
+
+// newLocalVolume creates a volume for local and hostPath PVs, it must be called before the informers started.
+func newLocalVolume(
+	podInformer coreinformers.PodInformer,
+	nodeLister corelisters.NodeLister) (volume, error) {
+	informer := podInformer.Informer()
+	if err := util.AddPodClaimIndex(informer); err != nil {
+		return nil, err
+	}
+	return &localVolume{
+		podIndexer: informer.GetIndexer(),
+		nodeLister: nodeLister,
+	}, nil
+}
+
+// localVolume is a wrapper of local and hostPath volumes. The volume is only accessible
+// on the nodes selected by the node affinity of the PV, so workloads whose pods can't be
+// scheduled to any of them are rejected. Usage comes from the kubelet volume stats.
+type localVolume struct {
+	podIndexer cache.Indexer
+	nodeLister corelisters.NodeLister
+}
+
+// Start starts the volume.
+func (v *localVolume) Start(stopCh <-chan struct{}) error {
+	return nil
+}
+
+// Available returns true if the volume can be mounted by a workload.
+func (v *localVolume) Available(
+	w *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
+	return accessModesAvailable(w, pv, pvcr)
+}
+
+// Schedulable returns an error if pods of a workload can't be scheduled to any node the volume pinned to.
+func (v *localVolume) Schedulable(pv *corev1.PersistentVolume, podSpecs []*corev1.PodSpec) error {
+	selector := volumeNodeSelector(pv)
+	if selector == nil {
+		return nil
+	}
+	nodes, err := v.pinnedNodes(selector)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return k8serrors.NewBadRequest(
+			fmt.Sprintf("no node matches the node affinity of local volume %s", pv.Name))
+	}
+
+	for _, spec := range podSpecs {
+		schedulable := false
+		for _, node := range nodes {
+			matched, err := podSpecMatchesNode(spec, node)
+			if err != nil {
+				return k8serrors.NewBadRequest(fmt.Sprintf("invalid node affinity of workload: %v", err))
+			}
+			if matched {
+				schedulable = true
+				break
+			}
+		}
+		if !schedulable {
+			return k8serrors.NewBadRequest(fmt.Sprintf(
+				"pods of the workload cannot be scheduled to node %s which local volume %s is pinned to",
+				nodeNames(nodes), pv.Name))
+		}
+	}
+	return nil
+}
+
+// MountedNodes returns the nodes selected by the node affinity of the PV, or the nodes
+// of the scheduled pods using the volume if the PV has no node affinity.
+func (v *localVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
+	selector := volumeNodeSelector(pv)
+	if selector == nil {
+		return podMountedNodes(v.podIndexer, v.nodeLister, pv, false)
+	}
+	nodes, err := v.pinnedNodes(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	mountedNodes := make([]storagev2.MountedNode, 0, len(nodes))
+	for _, node := range nodes {
+		address, err := nodeAddress(v.nodeLister, node.Name)
+		if err != nil {
+			return nil, err
+		}
+		mountedNodes = append(mountedNodes, storagev2.MountedNode{
+			NodeName:   node.Name,
+			Address:    address,
+			AccessMode: storagev2.MountAccessReadWrite,
+		})
+	}
+	sort.Slice(mountedNodes, func(i, j int) bool { return mountedNodes[i].Address < mountedNodes[j].Address })
+
+	return mountedNodes, nil
+}
+
+// Usage returns current usage of the volume, all fields are left to the kubelet volume stats.
+func (v *localVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	return &types.VolumeUsage{}, nil
+}
+
+// Attributes returns the path of the volume on the node.
+func (v *localVolume) Attributes(pv *corev1.PersistentVolume) (map[string]string, error) {
+	if pv.Spec.Local != nil {
+		return map[string]string{"path": pv.Spec.Local.Path}, nil
+	}
+	return map[string]string{"path": pv.Spec.HostPath.Path}, nil
+}
+
+// pinnedNodes returns the nodes matching a node selector.
+func (v *localVolume) pinnedNodes(selector *corev1.NodeSelector) ([]*corev1.Node, error) {
+	nodes, err := v.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list nodes failed: %v", err)
+	}
+	var pinned []*corev1.Node
+	for _, node := range nodes {
+		matched, err := nodeSelectorMatches(selector, node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node affinity: %v", err)
+		}
+		if matched {
+			pinned = append(pinned, node)
+		}
+	}
+	return pinned, nil
+}
+
+// volumeNodeSelector returns the required node selector of a PV, or nil if not specified.
+func volumeNodeSelector(pv *corev1.PersistentVolume) *corev1.NodeSelector {
+	if pv.Spec.NodeAffinity == nil {
+		return nil
+	}
+	return pv.Spec.NodeAffinity.Required
+}
+
+// podSpecMatchesNode returns true if the node name, node selector and required node
+// affinity of a pod spec allow it to be scheduled to a node. Taints are not considered.
+func podSpecMatchesNode(spec *corev1.PodSpec, node *corev1.Node) (bool, error) {
+	if len(spec.NodeName) > 0 && spec.NodeName != node.Name {
+		return false, nil
+	}
+	if len(spec.NodeSelector) > 0 &&
+		!labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false, nil
+	}
+	affinity := spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+	return nodeSelectorMatches(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution, node)
+}
+
+// nodeSelectorMatches returns true if a node matches any term of a node selector.
+func nodeSelectorMatches(selector *corev1.NodeSelector, node *corev1.Node) (bool, error) {
+	for _, term := range selector.NodeSelectorTerms {
+		matched, err := nodeSelectorTermMatches(&term, node)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// nodeSelectorTermMatches returns true if a node matches all requirements of a term,
+// an empty term matches no nodes.
+func nodeSelectorTermMatches(term *corev1.NodeSelectorTerm, node *corev1.Node) (bool, error) {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false, nil
+	}
+	if len(term.MatchExpressions) > 0 {
+		selector, err := nodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(node.Labels)) {
+			return false, nil
+		}
+	}
+	if len(term.MatchFields) > 0 {
+		for _, req := range term.MatchFields {
+			if req.Key != nodeNameField {
+				return false, fmt.Errorf("unsupported field %q", req.Key)
+			}
+		}
+		selector, err := nodeSelectorRequirementsAsSelector(term.MatchFields)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set{nodeNameField: node.Name}) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// nodeSelectorRequirementsAsSelector converts node selector requirements to a label selector.
+func nodeSelectorRequirementsAsSelector(reqs []corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	selector := labels.NewSelector()
+	for _, req := range reqs {
+		var op selection.Operator
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn:
+			op = selection.In
+		case corev1.NodeSelectorOpNotIn:
+			op = selection.NotIn
+		case corev1.NodeSelectorOpExists:
+			op = selection.Exists
+		case corev1.NodeSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		case corev1.NodeSelectorOpGt:
+			op = selection.GreaterThan
+		case corev1.NodeSelectorOpLt:
+			op = selection.LessThan
+		default:
+			return nil, fmt.Errorf("%q is not a valid node selector operator", req.Operator)
+		}
+		r, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*r)
+	}
+	return selector, nil
+}
+
+// nodeNames returns the names of nodes joined by commas.
+func nodeNames(nodes []*corev1.Node) string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -22,14 +22,20 @@ import (
 	"fmt"
 	"strings"
 
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
 	"tkestack.io/volume-decorator/pkg/config"
 	clientset "tkestack.io/volume-decorator/pkg/generated/clientset/versioned"
-	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v1"
+	pvcrlisters "tkestack.io/volume-decorator/pkg/generated/listers/storage/v2"
+	"tkestack.io/volume-decorator/pkg/nodes"
+	"tkestack.io/volume-decorator/pkg/tencentcloud"
 	"tkestack.io/volume-decorator/pkg/types"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	storageinformers "k8s.io/client-go/informers/storage/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 )
@@ -44,32 +50,109 @@ type Manager interface {
 	// Start starts the manager.
 	Start(stopCh <-chan struct{}) error
 	// Status returns the getPVCStatus of a PVC/PV.
-	Status(namespace, name string) ([]storagev1alpha1.PersistentVolumeClaimStatus, error)
-	// Attach attaches a volume to a workload.
-	Attach(w *storagev1alpha1.Workload, namespace, name string) error
-	// MountedNodes returns the node list this volume mounted on.
-	MountedNodes(namespace, name string) ([]string, error)
-	// Usage returns the real usage of volume in byte.
-	Usage(namespace, name string) (int64, error)
+	Status(namespace, name string) ([]storagev2.PersistentVolumeClaimStatus, error)
+	// Attach attaches a volume to a workload, podSpecs are the pod templates of
+	// the workload checked by the volumes only accessible on some nodes.
+	Attach(w *storagev2.Workload, podSpecs []*corev1.PodSpec, namespace, name string) error
+	// MountedNodes returns the node list this volume mounted on, only NodeName,
+	// Address and AccessMode of the records are filled.
+	MountedNodes(namespace, name string) ([]storagev2.MountedNode, error)
+	// Usage returns the real usage of volume, kubelet of nodeNames will be
+	// queried for the fields the storage backend doesn't know.
+	Usage(namespace, name string, nodeNames []string) (*types.VolumeUsage, error)
+	// Attributes returns the backend specific attributes of a volume, nil if
+	// the storage backend doesn't know any.
+	Attributes(namespace, name string) (map[string]string, error)
+	// Quota returns the quota of a volume in bytes enforced by the storage backend, 0 if
+	// not set, or nil if the storage backend doesn't manage the quota of the volume.
+	Quota(namespace, name string) (*int64, error)
+	// SetQuota sets the quota of a volume in bytes.
+	SetQuota(namespace, name string, bytes int64) error
 }
 
-// New creates a new manager.
+// New creates a new manager, it must be called before the informers started.
 func New(
-	config *config.VolumeConfig,
+	cfg *config.VolumeConfig,
 	pvcrClient clientset.Interface,
 	pvLister corelisters.PersistentVolumeLister,
 	pvcLister corelisters.PersistentVolumeClaimLister,
-	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister) Manager {
-	volumes := make(map[types.VolumeType]volume)
-	for _, typ := range strings.Split(config.Types, ",") {
-		switch typ {
-		case types.CephFS:
-			volumes[types.CephFS] = newCephFSVolume(config)
-		case types.CephRBD:
-			volumes[types.CephRBD] = newCephRBDVolume(config)
-		case types.TencentCBS:
-			volumes[types.TencentCBS] = newCBSVolume()
+	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister,
+	attachmentInformer storageinformers.VolumeAttachmentInformer,
+	nodeInformer coreinformers.NodeInformer,
+	podInformer coreinformers.PodInformer,
+	secretClient corev1client.SecretsGetter,
+	kubeletUsages *nodes.VolumeUsageCollector) (Manager, error) {
+	backendConfig, err := config.LoadBackendConfig(cfg.BackendConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	newBackend := func(typ string, cfg *config.VolumeConfig) (volume, error) {
+		return newVolume(typ, cfg, attachmentInformer, nodeInformer, podInformer, secretClient)
+	}
+
+	genericVolume, err := newBackend(config.GenericBackend, cfg)
+	if err != nil {
+		return nil, err
+	}
+	backends := map[string]volume{
+		config.GenericBackend:   genericVolume,
+		config.UnmanagedBackend: unmanagedVolume{},
+	}
+	drivers := make(map[string]string)
+
+	// Volume types enabled by flags serve their own driver names with the flag settings.
+	for _, typ := range strings.Split(cfg.Types, ",") {
+		vol, err := newBackend(typ, cfg)
+		if err != nil {
+			return nil, err
 		}
+		if vol == nil {
+			continue
+		}
+		backends[typ] = vol
+		drivers[typ] = typ
+		for _, driver := range typeDrivers[typ] {
+			drivers[driver] = typ
+		}
+	}
+
+	for name, backend := range backendConfig.Backends {
+		if _, exist := backends[name]; exist {
+			return nil, fmt.Errorf("backend %s is already defined", name)
+		}
+		backendCfg, err := cfg.WithSettings(backend.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backend %s: %v", name, err)
+		}
+		vol, err := newBackend(backend.Type, backendCfg)
+		if err != nil {
+			return nil, err
+		}
+		if vol == nil {
+			return nil, fmt.Errorf("unknown type %s of backend %s", backend.Type, name)
+		}
+		backends[name] = vol
+	}
+
+	for driver, name := range backendConfig.Drivers {
+		if _, exist := backends[name]; !exist {
+			return nil, fmt.Errorf("unknown backend %s of driver %s", name, driver)
+		}
+		drivers[driver] = name
+	}
+	storageClasses := make(map[string]string)
+	for class, name := range backendConfig.StorageClasses {
+		if _, exist := backends[name]; !exist {
+			return nil, fmt.Errorf("unknown backend %s of StorageClass %s", name, class)
+		}
+		storageClasses[class] = name
+	}
+	defaultBackend := config.GenericBackend
+	if len(backendConfig.DefaultBackend) > 0 {
+		defaultBackend = backendConfig.DefaultBackend
+	}
+	if _, exist := backends[defaultBackend]; !exist {
+		return nil, fmt.Errorf("unknown default backend %s", defaultBackend)
 	}
 
 	return &manager{
@@ -78,8 +161,45 @@ func New(
 		pvcLister:  pvcLister,
 		pvcrLister: pvcrLister,
 
-		volumes: volumes,
+		kubeletUsages:  kubeletUsages,
+		backends:       backends,
+		drivers:        drivers,
+		storageClasses: storageClasses,
+		defaultBackend: defaultBackend,
+	}, nil
+}
+
+// typeDrivers are the CSI driver names served by volume types besides their own names.
+var typeDrivers = map[types.VolumeType][]string{
+	types.CephRBD: {types.CephRBDCSI},
+	types.CephFS:  {types.CephFSCSI},
+	types.NFS:     {types.NFSCSI},
+}
+
+// newVolume creates a volume of a type, or nil if the type is unknown.
+func newVolume(
+	typ types.VolumeType,
+	cfg *config.VolumeConfig,
+	attachmentInformer storageinformers.VolumeAttachmentInformer,
+	nodeInformer coreinformers.NodeInformer,
+	podInformer coreinformers.PodInformer,
+	secretClient corev1client.SecretsGetter) (volume, error) {
+	switch typ {
+	case config.GenericBackend:
+		return newGenericCSIVolume(attachmentInformer, podInformer, nodeInformer.Lister())
+	case types.CephFS:
+		return newCephFSVolume(cfg, secretClient)
+	case types.CephRBD:
+		return newCephRBDVolume(cfg, secretClient)
+	case types.NFS:
+		return newNFSVolume(cfg, podInformer, nodeInformer.Lister())
+	case types.Local:
+		return newLocalVolume(podInformer, nodeInformer.Lister())
+	case types.TencentCBS:
+		return newCBSVolume(tencentcloud.NewClient(&cfg.TencentCloudConfig),
+			cfg.TencentCloudConfig.CacheTTL, nodeInformer)
 	}
+	return nil, nil
 }
 
 // manager is a common framework implements Manager.
@@ -89,12 +209,21 @@ type manager struct {
 	pvcLister  corelisters.PersistentVolumeClaimLister
 	pvcrLister pvcrlisters.PersistentVolumeClaimRuntimeLister
 
-	volumes map[types.VolumeType]volume
+	kubeletUsages *nodes.VolumeUsageCollector
+	// backends are the volumes keyed by backend names.
+	backends map[string]volume
+	// drivers maps volume types and CSI driver names to backend names.
+	drivers map[string]string
+	// storageClasses maps StorageClass names to backend names.
+	storageClasses map[string]string
+	// defaultBackend serves the volumes not mapped to any backend.
+	defaultBackend string
 }
 
 // Start starts the manager.
 func (m *manager) Start(stopCh <-chan struct{}) error {
-	for _, volume := range m.volumes {
+	m.kubeletUsages.Start(stopCh)
+	for _, volume := range m.backends {
 		if err := volume.Start(stopCh); err != nil {
 			return err
 		}
@@ -103,7 +232,7 @@ func (m *manager) Start(stopCh <-chan struct{}) error {
 }
 
 // Status returns the getPVCStatus of a PVC/PV.
-func (m *manager) Status(namespace, name string) ([]storagev1alpha1.PersistentVolumeClaimStatus, error) {
+func (m *manager) Status(namespace, name string) ([]storagev2.PersistentVolumeClaimStatus, error) {
 	pvc, err := m.pvcLister.PersistentVolumeClaims(namespace).Get(name)
 	if err != nil {
 		return nil, err
@@ -120,7 +249,7 @@ func (m *manager) Status(namespace, name string) ([]storagev1alpha1.PersistentVo
 }
 
 // Attach attaches a volume to a workload.
-func (m *manager) Attach(w *storagev1alpha1.Workload, namespace, name string) error {
+func (m *manager) Attach(w *storagev2.Workload, podSpecs []*corev1.PodSpec, namespace, name string) error {
 	klog.V(4).Infof("Try to attach volume %s/%s to workload %+v",
 		namespace, name, w)
 
@@ -133,30 +262,40 @@ func (m *manager) Attach(w *storagev1alpha1.Workload, namespace, name string) er
 		return err
 	}
 
-	for i := range pvcr.Spec.Workloads {
-		if sameWorkload(w, &pvcr.Spec.Workloads[i]) {
-			return nil
-		}
+	key := storagev2.WorkloadKey(w)
+	if _, exist := pvcr.Status.Workloads[key]; exist {
+		return nil
 	}
 
-	if err = vol.Available(w, pvcr); err != nil {
+	if err = vol.Available(w, pv, pvcr); err != nil {
 		return err
 	}
+	if checker, ok := vol.(schedulingChecker); ok {
+		if err = checker.Schedulable(pv, podSpecs); err != nil {
+			return err
+		}
+	}
 
+	now := metav1.Now()
 	newPVCR := pvcr.DeepCopy()
-	newPVCR.Spec.Workloads = append(newPVCR.Spec.Workloads, *w)
+	if newPVCR.Status.Workloads == nil {
+		newPVCR.Status.Workloads = make(map[string]storagev2.Workload)
+	}
+	newPVCR.Status.Workloads[key] = *w
+	newPVCR.Status.LastUpdated.Workloads = &now
 	statuses, err := getPVCStatus(pvc, pv, newPVCR)
 	if err != nil {
 		return err
 	}
-	newPVCR.Spec.Statuses = statuses
+	newPVCR.Status.SetStatuses(statuses)
+	newPVCR.Status.ObservedGeneration = pvcr.Generation
 
-	_, err = m.pvcrClient.StorageV1().PersistentVolumeClaimRuntimes(newPVCR.Namespace).Update(newPVCR)
+	_, err = m.pvcrClient.StorageV2().PersistentVolumeClaimRuntimes(newPVCR.Namespace).UpdateStatus(newPVCR)
 	return err
 }
 
 // MountedNodes returns the node list this volume mounted on.
-func (m *manager) MountedNodes(namespace, name string) ([]string, error) {
+func (m *manager) MountedNodes(namespace, name string) ([]storagev2.MountedNode, error) {
 	_, pv, vol, err := m.getVolume(namespace, name)
 	if err != nil {
 		return nil, err
@@ -164,13 +303,63 @@ func (m *manager) MountedNodes(namespace, name string) ([]string, error) {
 	return vol.MountedNodes(pv)
 }
 
-// Usage returns the real usage of volume in byte.
-func (m *manager) Usage(namespace, name string) (int64, error) {
+// Usage returns the real usage of volume. The storage backend is preferred,
+// then the kubelet volume stats, and the capacity of PV at last.
+func (m *manager) Usage(namespace, name string, nodeNames []string) (*types.VolumeUsage, error) {
 	_, pv, vol, err := m.getVolume(namespace, name)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	usage, err := vol.Usage(pv)
+	if err != nil {
+		return nil, err
+	}
+	if kubeletUsage, exist := m.kubeletUsages.GetUsage(namespace, name, nodeNames); exist {
+		usage.Merge(kubeletUsage)
 	}
-	return vol.Usage(pv)
+	if capacity, exist := pv.Spec.Capacity[corev1.ResourceStorage]; exist {
+		usage.Merge(&types.VolumeUsage{CapacityBytes: capacity.Value()})
+	}
+	return usage, nil
+}
+
+// Attributes returns the backend specific attributes of a volume.
+func (m *manager) Attributes(namespace, name string) (map[string]string, error) {
+	_, pv, vol, err := m.getVolume(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	getter, ok := vol.(attributesGetter)
+	if !ok {
+		return nil, nil
+	}
+	return getter.Attributes(pv)
+}
+
+// Quota returns the quota of a volume.
+func (m *manager) Quota(namespace, name string) (*int64, error) {
+	_, pv, vol, err := m.getVolume(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	quotaManager, ok := vol.(quotaManager)
+	if !ok {
+		return nil, nil
+	}
+	return quotaManager.Quota(pv)
+}
+
+// SetQuota sets the quota of a volume.
+func (m *manager) SetQuota(namespace, name string, bytes int64) error {
+	_, pv, vol, err := m.getVolume(namespace, name)
+	if err != nil {
+		return err
+	}
+	quotaManager, ok := vol.(quotaManager)
+	if !ok {
+		return fmt.Errorf("quota of %s/%s is not managed by its storage backend", namespace, name)
+	}
+	return quotaManager.SetQuota(pv, bytes)
 }
 
 // getVolume returns detail information of a volume.
@@ -187,46 +376,81 @@ func (m *manager) getVolume(
 	if pv == nil {
 		return nil, nil, nil, errors.New("volume is still creating")
 	}
-	if pv.Spec.CSI == nil {
-		return nil, nil, nil, k8serrors.NewBadRequest("not a CSI volume")
-	}
-	vol, exist := m.volumes[pv.Spec.CSI.Driver]
-	if !exist {
-		return nil, nil, nil, k8serrors.NewBadRequest(fmt.Sprintf("unsupported volume type: %s", pv.Spec.CSI.Driver))
+	vol, err := m.backend(pv)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	return pvc, pv, vol, nil
 }
 
+// backend returns the backend serving a PV. The StorageClass mapping is preferred, then the
+// mapping of the volume type, and volumes of unsupported sources are only served if the
+// default backend is unmanaged.
+func (m *manager) backend(pv *corev1.PersistentVolume) (volume, error) {
+	if name, exist := m.storageClasses[pv.Spec.StorageClassName]; exist {
+		return m.backends[name], nil
+	}
+	typ, err := volumeType(pv)
+	if err != nil {
+		if m.defaultBackend == config.UnmanagedBackend {
+			return m.backends[config.UnmanagedBackend], nil
+		}
+		return nil, err
+	}
+	if name, exist := m.drivers[typ]; exist {
+		return m.backends[name], nil
+	}
+	return m.backends[m.defaultBackend], nil
+}
+
+// volumeType returns the type of a volume. In-tree volumes are served by the
+// backends of their CSI counterparts.
+func volumeType(pv *corev1.PersistentVolume) (types.VolumeType, error) {
+	switch {
+	case pv.Spec.CSI != nil:
+		return pv.Spec.CSI.Driver, nil
+	case pv.Spec.RBD != nil:
+		return types.CephRBD, nil
+	case pv.Spec.CephFS != nil:
+		return types.CephFS, nil
+	case pv.Spec.NFS != nil:
+		return types.NFS, nil
+	case pv.Spec.Local != nil, pv.Spec.HostPath != nil:
+		return types.Local, nil
+	}
+	return "", k8serrors.NewBadRequest("unsupported volume source")
+}
+
 // getPVCStatus returns the status of a volume.
 func getPVCStatus(
 	pvc *corev1.PersistentVolumeClaim,
 	pv *corev1.PersistentVolume,
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) ([]storagev1alpha1.PersistentVolumeClaimStatus, error) {
+	pvcr *storagev2.PersistentVolumeClaimRuntime) ([]storagev2.PersistentVolumeClaimStatus, error) {
 	if pvc.DeletionTimestamp != nil {
-		return []storagev1alpha1.PersistentVolumeClaimStatus{storagev1alpha1.ClaimStatusDeleting}, nil
+		return []storagev2.PersistentVolumeClaimStatus{storagev2.ClaimStatusDeleting}, nil
 	}
 
-	var statuses []storagev1alpha1.PersistentVolumeClaimStatus
+	var statuses []storagev2.PersistentVolumeClaimStatus
 
 	switch pvc.Status.Phase {
 	case corev1.ClaimPending:
-		return []storagev1alpha1.PersistentVolumeClaimStatus{storagev1alpha1.ClaimStatusCreating}, nil
+		return []storagev2.PersistentVolumeClaimStatus{storagev2.ClaimStatusCreating}, nil
 	case corev1.ClaimLost:
-		return []storagev1alpha1.PersistentVolumeClaimStatus{storagev1alpha1.ClaimStatusLost}, nil
+		return []storagev2.PersistentVolumeClaimStatus{storagev2.ClaimStatusLost}, nil
 	case corev1.ClaimBound:
 		if pv == nil {
-			return []storagev1alpha1.PersistentVolumeClaimStatus{storagev1alpha1.ClaimStatusLost}, nil
+			return []storagev2.PersistentVolumeClaimStatus{storagev2.ClaimStatusLost}, nil
 		}
-		if pvcr != nil && len(pvcr.Spec.Workloads) > 0 {
-			statuses = append(statuses, storagev1alpha1.ClaimStatusInUse)
+		if pvcr != nil && len(pvcr.Status.Workloads) > 0 {
+			statuses = append(statuses, storagev2.ClaimStatusInUse)
 		} else {
-			statuses = append(statuses, storagev1alpha1.ClaimStatusAvailable)
+			statuses = append(statuses, storagev2.ClaimStatusAvailable)
 		}
 	}
 
 	for _, condition := range pvc.Status.Conditions {
 		if resizeConditions[condition.Type] && condition.Status == corev1.ConditionTrue {
-			statuses = append(statuses, storagev1alpha1.ClaimStatusExpanding)
+			statuses = append(statuses, storagev2.ClaimStatusExpanding)
 		}
 	}
 
@@ -0,0 +1,269 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/config"
+	"tkestack.io/volume-decorator/pkg/types"
+	"tkestack.io/volume-decorator/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// newNFSVolume creates a volume for NFS storage, it must be called before the informers started.
+func newNFSVolume(
+	config *config.VolumeConfig,
+	podInformer coreinformers.PodInformer,
+	nodeLister corelisters.NodeLister) (volume, error) {
+	informer := podInformer.Informer()
+	if err := util.AddPodClaimIndex(informer); err != nil {
+		return nil, err
+	}
+	runner, err := newCommandRunner(config)
+	if err != nil {
+		return nil, err
+	}
+	return &nfsVolume{
+		commandRunner: runner,
+		rootMountPath: config.NFSConfig.RootMountPath,
+		mountOptions:  config.NFSConfig.MountOptions,
+		podIndexer:    informer.GetIndexer(),
+		nodeLister:    nodeLister,
+		mounted:       sets.NewString(),
+		exports:       make(map[string]*nfsExports),
+	}, nil
+}
+
+// nfsVolume is a wrapper of NFS volume, both in-tree and CSI volumes are supported.
+// Exports are mounted under rootMountPath to collect the usage.
+type nfsVolume struct {
+	*commandRunner
+	rootMountPath string
+	mountOptions  string
+	podIndexer    cache.Indexer
+	nodeLister    corelisters.NodeLister
+
+	// lock protects mounted, which is the set of exports(server:share) mounted, and exports.
+	lock    sync.Mutex
+	mounted sets.String
+	// exports are the exports listed from the servers of in-tree volumes, keyed by servers.
+	exports map[string]*nfsExports
+}
+
+// nfsExports is the exported paths of a server, empty if they can't be listed.
+type nfsExports struct {
+	paths    sets.String
+	listedAt time.Time
+}
+
+// nfsExportsTTL is how long the exports of a server are cached.
+const nfsExportsTTL = time.Minute * 10
+
+// Start starts the volume.
+func (v *nfsVolume) Start(stopCh <-chan struct{}) error {
+	v.stopOn(stopCh)
+	if err := os.MkdirAll(v.rootMountPath, 0700); err != nil {
+		return fmt.Errorf("create nfs root mount point %s failed: %v", v.rootMountPath, err)
+	}
+	return nil
+}
+
+// Available returns true if the volume can be mounted by a workload. NFS volumes
+// can be shared by any workloads, unless the volume is read only.
+func (v *nfsVolume) Available(
+	w *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
+	if !w.ReadOnly && getNFSInfo(pv).ReadOnly {
+		return k8serrors.NewBadRequest("read only NFS volume cannot be mounted as ReadWrite mode")
+	}
+	return nil
+}
+
+// MountedNodes returns the nodes of the scheduled pods using the volume, since
+// NFS servers don't tell their clients.
+func (v *nfsVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
+	return podMountedNodes(v.podIndexer, v.nodeLister, pv, getNFSInfo(pv).ReadOnly)
+}
+
+// Usage returns current usage of the volume. The file system stats are used if the
+// volume is known to be a whole export, otherwise the directory is walked by du, and the
+// capacity is left to the kubelet volume stats.
+func (v *nfsVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	info := getNFSInfo(pv)
+	mountPath, err := v.mountExport(info)
+	if err != nil {
+		return nil, err
+	}
+
+	wholeExport := len(info.SubDir) == 0
+	if wholeExport && info.InTree {
+		// The path of an in-tree volume may be a dir of an export shared by other volumes,
+		// such as the ones of the nfs-subdir provisioners.
+		wholeExport = v.isExport(info.Server, info.Share)
+	}
+	if wholeExport {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(mountPath, &stat); err != nil {
+			return nil, fmt.Errorf("statfs %s failed: %v", mountPath, err)
+		}
+		blockSize := int64(stat.Bsize)
+		return &types.VolumeUsage{
+			UsedBytes:     int64(stat.Blocks-stat.Bfree) * blockSize,
+			CapacityBytes: int64(stat.Blocks) * blockSize,
+			InodesUsed:    int64(stat.Files - stat.Ffree),
+			InodesTotal:   int64(stat.Files),
+		}, nil
+	}
+
+	path := filepath.Join(mountPath, info.SubDir)
+	usedBytes, err := v.diskUsage(path, "-B1")
+	if err != nil {
+		return nil, fmt.Errorf("get usage of %s failed: %v", pv.Name, err)
+	}
+	usedInodes, err := v.diskUsage(path, "--inodes")
+	if err != nil {
+		return nil, fmt.Errorf("get inodes of %s failed: %v", pv.Name, err)
+	}
+	return &types.VolumeUsage{UsedBytes: usedBytes, InodesUsed: usedInodes}, nil
+}
+
+// Attributes returns the server and the path of the volume.
+func (v *nfsVolume) Attributes(pv *corev1.PersistentVolume) (map[string]string, error) {
+	info := getNFSInfo(pv)
+	return map[string]string{
+		"server": info.Server,
+		"path":   filepath.Join("/", info.Share, info.SubDir),
+	}, nil
+}
+
+// mountExport mounts the export of a volume if it is not mounted yet, and returns the mount path.
+func (v *nfsVolume) mountExport(info *nfsInfo) (string, error) {
+	export := info.Server + ":" + info.Share
+	mountPath := filepath.Join(v.rootMountPath, url.PathEscape(info.Server), url.PathEscape(info.Share))
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.mounted.Has(export) {
+		return mountPath, nil
+	}
+
+	if err := os.MkdirAll(mountPath, 0700); err != nil {
+		return "", fmt.Errorf("create nfs mount point %s failed: %v", mountPath, err)
+	}
+	// The export may be mounted by a previous process.
+	if _, err := v.execCommand("mountpoint", []string{"-q", mountPath}); err != nil {
+		klog.Infof("Mount nfs export %s to %s", export, mountPath)
+		args := []string{"-t", "nfs", export, mountPath}
+		if len(v.mountOptions) > 0 {
+			args = append([]string{"-o", v.mountOptions}, args...)
+		}
+		if _, err := v.execCommand("mount", args); err != nil {
+			return "", fmt.Errorf("mount nfs export %s failed: %v", export, err)
+		}
+	}
+	v.mounted.Insert(export)
+
+	return mountPath, nil
+}
+
+// isExport returns true if path is exported by server, as listed by showmount. False is
+// returned if the exports can't be listed, e.g. the server only serves NFSv4.
+func (v *nfsVolume) isExport(server, path string) bool {
+	v.lock.Lock()
+	exports, exist := v.exports[server]
+	v.lock.Unlock()
+	if !exist || time.Since(exports.listedAt) > nfsExportsTTL {
+		exports = &nfsExports{paths: sets.NewString(), listedAt: time.Now()}
+		output, err := v.execCommand("showmount", []string{"-e", "--no-headers", server})
+		if err != nil {
+			klog.Warningf("List exports of nfs server %s failed, its in-tree volumes are treated as dirs: %v",
+				server, err)
+		}
+		// Example: "/exports/data 10.0.0.0/8,192.168.0.0/16".
+		for _, line := range strings.Split(string(output), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				exports.paths.Insert(filepath.Clean(fields[0]))
+			}
+		}
+		v.lock.Lock()
+		v.exports[server] = exports
+		v.lock.Unlock()
+	}
+	return exports.paths.Has(filepath.Clean(path))
+}
+
+// diskUsage returns the summarized usage of a dir reported by du with extra args.
+func (v *nfsVolume) diskUsage(path string, args ...string) (int64, error) {
+	output, err := v.execCmd(longCmdTimeout, "du", append(append([]string{"-s"}, args...), path)...)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected output of du: %s", string(output))
+	}
+	value, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s failed: %v", fields[0], err)
+	}
+	return value, nil
+}
+
+// getNFSInfo extracts NFS information from volume, both CSI and in-tree volumes are supported.
+func getNFSInfo(pv *corev1.PersistentVolume) *nfsInfo {
+	if source := pv.Spec.NFS; source != nil {
+		return &nfsInfo{Server: source.Server, Share: source.Path, ReadOnly: source.ReadOnly, InTree: true}
+	}
+	attributes := pv.Spec.CSI.VolumeAttributes
+	return &nfsInfo{
+		Server:   attributes["server"],
+		Share:    attributes["share"],
+		SubDir:   attributes["subdir"],
+		ReadOnly: pv.Spec.CSI.ReadOnly,
+	}
+}
+
+// nfsInfo is a set of information of a NFS volume.
+type nfsInfo struct {
+	Server string
+	// Share is the exported path.
+	Share string
+	// SubDir is the dir of the volume in the export, empty if the volume is the whole export.
+	SubDir   string
+	ReadOnly bool
+	// InTree is true for in-tree volumes, whose Share is the path of the volume which may not be an export.
+	InTree bool
+}
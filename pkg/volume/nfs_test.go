@@ -0,0 +1,126 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// fakeNFSExecutor serves showmount and du, and reports every path as mounted.
+type fakeNFSExecutor struct {
+	exports string
+	// showmounts is the count of showmount calls.
+	showmounts int
+}
+
+func (e *fakeNFSExecutor) Exec(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	switch name {
+	case "mountpoint":
+		return nil, nil
+	case "showmount":
+		e.showmounts++
+		if len(e.exports) == 0 {
+			return nil, fmt.Errorf("rpc mount export: RPC: Unable to receive")
+		}
+		return []byte(e.exports), nil
+	case "du":
+		if args[1] == "--inodes" {
+			return []byte("12\t" + args[2] + "\n"), nil
+		}
+		return []byte("4096\t" + args[2] + "\n"), nil
+	}
+	return nil, fmt.Errorf("unexpected command %s %v", name, args)
+}
+
+// newInTreeNFSPV creates an in-tree NFS PV.
+func newInTreeNFSPV(path string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-nfs"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{Server: "10.0.0.31", Path: path},
+			},
+		},
+	}
+}
+
+func TestNFSUsageOfInTreeVolumes(t *testing.T) {
+	root, err := ioutil.TempDir("", "nfs-root")
+	if err != nil {
+		t.Fatalf("Create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, c := range []struct {
+		name    string
+		exports string
+		path    string
+		whole   bool
+	}{
+		{
+			name:    "dedicated export",
+			exports: "/exports/data 10.0.0.0/8\n/exports/shared *\n",
+			path:    "/exports/data/",
+			whole:   true,
+		},
+		{
+			name:    "dir of a shared export",
+			exports: "/exports/data 10.0.0.0/8\n/exports/shared *\n",
+			path:    "/exports/shared/default-data-pvc-1",
+		},
+		{
+			name: "exports not listed",
+			path: "/exports/data",
+		},
+	} {
+		executor := &fakeNFSExecutor{exports: c.exports}
+		v := &nfsVolume{
+			commandRunner: &commandRunner{executor: executor, ctx: context.Background()},
+			rootMountPath: root,
+			mounted:       sets.NewString(),
+			exports:       make(map[string]*nfsExports),
+		}
+		pv := newInTreeNFSPV(c.path)
+		for i := 0; i < 2; i++ {
+			usage, err := v.Usage(pv)
+			if err != nil {
+				t.Fatalf("%s: get usage failed: %v", c.name, err)
+			}
+			if c.whole {
+				// The file system stats of the mount path are used.
+				if usage.CapacityBytes == 0 {
+					t.Errorf("%s: expected the capacity of the file system, got %+v", c.name, usage)
+				}
+			} else if usage.UsedBytes != 4096 || usage.InodesUsed != 12 || usage.CapacityBytes != 0 {
+				t.Errorf("%s: expected the usage by du, got %+v", c.name, usage)
+			}
+		}
+		if executor.showmounts != 1 {
+			t.Errorf("%s: expected the exports listed once, got %d", c.name, executor.showmounts)
+		}
+	}
+}
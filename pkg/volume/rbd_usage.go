@@ -0,0 +1,240 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	// rbdPoolDuTimeout is the timeout of `rbd du` for a whole pool, which may be slow for
+	// pools of thousands of images without the fast-diff feature.
+	rbdPoolDuTimeout = time.Minute * 30
+	// A pool failed to be collected is retried after rbdPoolRetryInitialDelay, and the
+	// delay is doubled for each consecutive failure up to rbdPoolRetryMaxDelay.
+	rbdPoolRetryInitialDelay = time.Minute
+	rbdPoolRetryMaxDelay     = time.Minute * 30
+	// rbdPoolMaxCollections is the max count of pools collected at the same time, so that a slow
+	// pool doesn't delay the others while the cluster is not flooded by `rbd du`.
+	rbdPoolMaxCollections = 4
+)
+
+// rbdExecutor executes a `rbd xxx` command against the pool of info.
+type rbdExecutor func(info *rbdInfo, timeout time.Duration, args ...string) ([]byte, error)
+
+// newRBDPoolUsages creates a rbdPoolUsages.
+func newRBDPoolUsages(exec rbdExecutor, maxStaleness time.Duration) *rbdPoolUsages {
+	return &rbdPoolUsages{
+		exec:         exec,
+		maxStaleness: maxStaleness,
+		collections:  make(chan struct{}, rbdPoolMaxCollections),
+		pools:        make(map[string]*rbdPoolUsage),
+	}
+}
+
+// rbdPoolUsages collects the usage of all images in a pool by one `rbd du` and caches
+// the results. Pools are added when the usage of their images is first queried, and
+// removed if not queried for twice of maxStaleness. Queries are only answered from the
+// cache, the collections run in background.
+type rbdPoolUsages struct {
+	exec         rbdExecutor
+	maxStaleness time.Duration
+	// collections limits the concurrent collections, each running one holds a slot.
+	collections chan struct{}
+
+	// lock protects pools and the fields of each pool, it is never held while collecting.
+	lock  sync.Mutex
+	pools map[string]*rbdPoolUsage
+}
+
+// rbdPoolUsage is the cached usage of the images in a pool.
+type rbdPoolUsage struct {
+	// info is an image of the pool, used to connect the cluster.
+	info          *rbdInfo
+	images        map[string]rbdImageUsage
+	collectedAt   time.Time
+	lastRequested time.Time
+
+	// collecting is true while the pool is being collected.
+	collecting bool
+	// failures is the count of consecutive failed collections, lastErr is the error of
+	// the latest one, and the pool is not collected again before retryAt.
+	failures int
+	lastErr  error
+	retryAt  time.Time
+}
+
+// rbdImageUsage is the usage of an image.
+type rbdImageUsage struct {
+	UsedBytes        int64
+	ProvisionedBytes int64
+}
+
+// Get returns the cached usage of an image. A pool never collected is collected in background,
+// and an error is returned until it is done. An error is also returned if the cache is older
+// than maxStaleness, or the image was created after the last collection.
+func (u *rbdPoolUsages) Get(info *rbdInfo) (*rbdImageUsage, error) {
+	key := rbdPoolKey(info)
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	pool, exist := u.pools[key]
+	if !exist {
+		pool = &rbdPoolUsage{}
+		u.pools[key] = pool
+	}
+	// Keep the latest credentials of the pool.
+	pool.info = info
+	pool.lastRequested = time.Now()
+
+	if pool.collectedAt.IsZero() {
+		if u.startCollect(pool) {
+			go u.collect(key, pool)
+		}
+		if pool.lastErr != nil {
+			return nil, fmt.Errorf("usage of rbd pool %s is not collected yet, the last collection failed: %v",
+				info.Pool, pool.lastErr)
+		}
+		return nil, fmt.Errorf("usage of rbd pool %s is not collected yet", info.Pool)
+	}
+	// The errors are recorded in the conditions of PVCRs, so they never carry the age of
+	// the usage, which changes every time.
+	if time.Since(pool.collectedAt) > u.maxStaleness {
+		if pool.lastErr != nil {
+			return nil, fmt.Errorf("usage of rbd pool %s is stale, not collected in %v, the last collection failed: %v",
+				info.Pool, u.maxStaleness, pool.lastErr)
+		}
+		return nil, fmt.Errorf("usage of rbd pool %s is stale, not collected in %v", info.Pool, u.maxStaleness)
+	}
+	usage, exist := pool.images[info.Image]
+	if !exist {
+		return nil, fmt.Errorf("usage of rbd image %s is not collected yet", info.Image)
+	}
+	return &usage, nil
+}
+
+// Refresh starts collecting all pools queried recently in background, and removes the others.
+func (u *rbdPoolUsages) Refresh() {
+	u.lock.Lock()
+	pools := make(map[string]*rbdPoolUsage, len(u.pools))
+	for key, pool := range u.pools {
+		if time.Since(pool.lastRequested) > 2*u.maxStaleness {
+			klog.Infof("Rbd pool %s is not used any more, stop collecting its usage", key)
+			delete(u.pools, key)
+			continue
+		}
+		if u.startCollect(pool) {
+			pools[key] = pool
+		}
+	}
+	u.lock.Unlock()
+
+	for key, pool := range pools {
+		go u.collect(key, pool)
+	}
+}
+
+// startCollect marks pool as collecting and returns true, unless it is being collected or
+// waiting to be retried after failures. u.lock must be held.
+func (u *rbdPoolUsages) startCollect(pool *rbdPoolUsage) bool {
+	if pool.collecting || time.Now().Before(pool.retryAt) {
+		return false
+	}
+	pool.collecting = true
+	return true
+}
+
+// collect runs `rbd du` for a pool marked by startCollect and caches the results, it waits
+// for a slot if too many pools are being collected.
+func (u *rbdPoolUsages) collect(key string, pool *rbdPoolUsage) {
+	u.collections <- struct{}{}
+	defer func() { <-u.collections }()
+
+	u.lock.Lock()
+	info := pool.info
+	u.lock.Unlock()
+
+	start := time.Now()
+	images, err := u.du(info)
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	pool.collecting = false
+	if err != nil {
+		pool.failures++
+		pool.lastErr = err
+		delay := rbdPoolRetryDelay(pool.failures)
+		pool.retryAt = time.Now().Add(delay)
+		klog.Errorf("Collect usage of rbd pool %s failed %d times, retry in %v: %v", key, pool.failures, delay, err)
+		return
+	}
+	klog.V(4).Infof("Usage of %d images in rbd pool %s collected in %v", len(images), info.Pool, time.Since(start))
+	pool.images = images
+	pool.collectedAt = time.Now()
+	pool.failures = 0
+	pool.lastErr = nil
+	pool.retryAt = time.Time{}
+}
+
+// du returns the usage of the images in the pool of info.
+func (u *rbdPoolUsages) du(info *rbdInfo) (map[string]rbdImageUsage, error) {
+	output, err := u.exec(info, rbdPoolDuTimeout, "du")
+	if err != nil {
+		return nil, fmt.Errorf("du rbd pool %s failed: %v", info.Pool, err)
+	}
+	// Snapshots are listed besides the images, only the images are concerned.
+	result := struct {
+		Images []struct {
+			Name            string `json:"name"`
+			Snapshot        string `json:"snapshot"`
+			ProvisionedSize int64  `json:"provisioned_size"`
+			UsedSize        int64  `json:"used_size"`
+		} `json:"images"`
+	}{}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal du result of rbd pool %s failed: %v", info.Pool, err)
+	}
+	images := make(map[string]rbdImageUsage, len(result.Images))
+	for _, image := range result.Images {
+		if len(image.Snapshot) == 0 {
+			images[image.Name] = rbdImageUsage{UsedBytes: image.UsedSize, ProvisionedBytes: image.ProvisionedSize}
+		}
+	}
+	return images, nil
+}
+
+// rbdPoolRetryDelay returns the delay before retrying a pool failed failures times in a row.
+func rbdPoolRetryDelay(failures int) time.Duration {
+	delay := rbdPoolRetryInitialDelay
+	for i := 1; i < failures && delay < rbdPoolRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > rbdPoolRetryMaxDelay {
+		return rbdPoolRetryMaxDelay
+	}
+	return delay
+}
+
+// rbdPoolKey returns the key of the pool of an image.
+func rbdPoolKey(info *rbdInfo) string {
+	return info.ClusterID + "/" + info.Monitors + "/" + info.Pool
+}
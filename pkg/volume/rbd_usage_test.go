@@ -0,0 +1,202 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const rbdTestPoolDu = `{"images":[` +
+	`{"name":"img-1","snapshot":"snap-1","provisioned_size":10485760,"used_size":4096},` +
+	`{"name":"img-1","provisioned_size":10485760,"used_size":1048576},` +
+	`{"name":"img-2","provisioned_size":20971520,"used_size":0}]}`
+
+// fakeRBDExecutor returns output or err for `rbd du`, and counts the calls.
+type fakeRBDExecutor struct {
+	lock   sync.Mutex
+	output string
+	err    error
+	calls  int
+}
+
+func (e *fakeRBDExecutor) exec(info *rbdInfo, timeout time.Duration, args ...string) ([]byte, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.calls++
+	return []byte(e.output), e.err
+}
+
+func (e *fakeRBDExecutor) callCount() int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.calls
+}
+
+// waitCollected waits until the background collection of info's pool is finished.
+func waitCollected(t *testing.T, usages *rbdPoolUsages, info *rbdInfo) {
+	for i := 0; i < 100; i++ {
+		usages.lock.Lock()
+		collecting := usages.pools[rbdPoolKey(info)].collecting
+		usages.lock.Unlock()
+		if !collecting {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Pool %s is not collected in time", info.Pool)
+}
+
+func TestRBDPoolUsages(t *testing.T) {
+	exec := &fakeRBDExecutor{output: rbdTestPoolDu}
+	usages := newRBDPoolUsages(exec.exec, time.Minute)
+	img1 := &rbdInfo{Pool: "rbd", Image: "img-1", ClusterID: "c1"}
+	img3 := &rbdInfo{Pool: "rbd", Image: "img-3", ClusterID: "c1"}
+
+	if _, err := usages.Get(img1); err == nil || !strings.Contains(err.Error(), "not collected yet") {
+		t.Fatalf("Expected not collected error, got %v", err)
+	}
+	waitCollected(t, usages, img1)
+
+	usage, err := usages.Get(img1)
+	if err != nil {
+		t.Fatalf("Get usage failed: %v", err)
+	}
+	if *usage != (rbdImageUsage{UsedBytes: 1048576, ProvisionedBytes: 10485760}) {
+		t.Errorf("Unexpected usage: %+v", *usage)
+	}
+	if _, err := usages.Get(img3); err == nil || !strings.Contains(err.Error(), "img-3 is not collected yet") {
+		t.Errorf("Expected not collected error of img-3, got %v", err)
+	}
+	if calls := exec.callCount(); calls != 1 {
+		t.Errorf("Expected 1 collection, got %d", calls)
+	}
+
+	usages.Refresh()
+	waitCollected(t, usages, img1)
+	if calls := exec.callCount(); calls != 2 {
+		t.Errorf("Expected 2 collections, got %d", calls)
+	}
+
+	var messages []string
+	for _, age := range []time.Duration{2 * time.Minute, 3 * time.Minute} {
+		usages.lock.Lock()
+		usages.pools[rbdPoolKey(img1)].collectedAt = time.Now().Add(-age)
+		usages.lock.Unlock()
+		_, err := usages.Get(img1)
+		if err == nil || !strings.Contains(err.Error(), "stale") {
+			t.Fatalf("Expected stale error, got %v", err)
+		}
+		messages = append(messages, err.Error())
+	}
+	// The error is recorded in a condition, which must not change as the usage ages.
+	if messages[0] != messages[1] {
+		t.Errorf("Expected the same stale error, got %q and %q", messages[0], messages[1])
+	}
+}
+
+func TestRBDPoolUsagesBackoff(t *testing.T) {
+	exec := &fakeRBDExecutor{err: errors.New("timed out")}
+	usages := newRBDPoolUsages(exec.exec, time.Minute)
+	info := &rbdInfo{Pool: "rbd", Image: "img-1", ClusterID: "c1"}
+
+	usages.Get(info)
+	waitCollected(t, usages, info)
+	for i := 0; i < 3; i++ {
+		if _, err := usages.Get(info); err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("Expected the collection error, got %v", err)
+		}
+	}
+	usages.Refresh()
+	if calls := exec.callCount(); calls != 1 {
+		t.Fatalf("Expected no retry before the backoff, got %d collections", calls)
+	}
+
+	usages.lock.Lock()
+	pool := usages.pools[rbdPoolKey(info)]
+	if pool.failures != 1 || time.Until(pool.retryAt) <= 0 {
+		t.Errorf("Unexpected failures %d and retry at %v", pool.failures, pool.retryAt)
+	}
+	pool.retryAt = time.Time{}
+	usages.lock.Unlock()
+
+	exec.lock.Lock()
+	exec.output, exec.err = rbdTestPoolDu, nil
+	exec.lock.Unlock()
+	usages.Refresh()
+	waitCollected(t, usages, info)
+	if _, err := usages.Get(info); err != nil {
+		t.Errorf("Get usage failed after retry: %v", err)
+	}
+	usages.lock.Lock()
+	if pool.failures != 0 || pool.lastErr != nil {
+		t.Errorf("Failures are not reset: %d, %v", pool.failures, pool.lastErr)
+	}
+	usages.lock.Unlock()
+}
+
+func TestRBDPoolUsagesSlowPool(t *testing.T) {
+	unblock := make(chan struct{})
+	exec := func(info *rbdInfo, timeout time.Duration, args ...string) ([]byte, error) {
+		if info.Pool == "slow" {
+			<-unblock
+		}
+		return []byte(rbdTestPoolDu), nil
+	}
+	usages := newRBDPoolUsages(exec, time.Minute)
+	slow := &rbdInfo{Pool: "slow", Image: "img-1"}
+	fast := &rbdInfo{Pool: "fast", Image: "img-1"}
+	usages.Get(slow)
+	usages.Get(fast)
+	waitCollected(t, usages, fast)
+
+	// The pools are collected concurrently, a slow pool doesn't delay the others.
+	usages.lock.Lock()
+	usages.pools[rbdPoolKey(fast)].collectedAt = time.Time{}
+	usages.lock.Unlock()
+	usages.Refresh()
+	waitCollected(t, usages, fast)
+	if _, err := usages.Get(fast); err != nil {
+		t.Errorf("Get usage of the fast pool failed: %v", err)
+	}
+	if _, err := usages.Get(slow); err == nil {
+		t.Errorf("Expected error of the slow pool not collected yet")
+	}
+	close(unblock)
+	waitCollected(t, usages, slow)
+}
+
+func TestRBDPoolRetryDelay(t *testing.T) {
+	for _, c := range []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{6, 30 * time.Minute},
+		{100, 30 * time.Minute},
+	} {
+		if delay := rbdPoolRetryDelay(c.failures); delay != c.delay {
+			t.Errorf("Delay of %d failures: expected %v, got %v", c.failures, c.delay, delay)
+		}
+	}
+}
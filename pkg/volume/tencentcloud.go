@@ -18,18 +18,70 @@
 package volume
 
 import (
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/tencentcloud"
+	"tkestack.io/volume-decorator/pkg/types"
 
 	corev1 "k8s.io/api/core/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// nodeInstanceIndex is the name of the index from CVM instance IDs to nodes.
+	nodeInstanceIndex = "instance"
+	// qcloudProviderPrefix is the prefix of the providerID of nodes in Tencent Cloud,
+	// for example, qcloud:///800002/ins-4w2cbmx6.
+	qcloudProviderPrefix = "qcloud://"
 )
 
-// newCBSVolume creates a cbsVolume.
-func newCBSVolume() volume {
-	return &cbsVolume{}
+// newCBSVolume creates a cbsVolume, it must be called before the informers started.
+func newCBSVolume(
+	client tencentcloud.Client,
+	cacheTTL time.Duration,
+	nodeInformer coreinformers.NodeInformer) (volume, error) {
+	informer := nodeInformer.Informer()
+	if _, exist := informer.GetIndexer().GetIndexers()[nodeInstanceIndex]; !exist {
+		if err := informer.AddIndexers(cache.Indexers{nodeInstanceIndex: indexNodeInstance}); err != nil {
+			return nil, fmt.Errorf("add node instance indexer failed: %v", err)
+		}
+	}
+	return &cbsVolume{
+		client:      client,
+		cacheTTL:    cacheTTL,
+		nodeIndexer: informer.GetIndexer(),
+		disks:       make(map[string]*cachedDisk),
+		instances:   make(map[string]*cachedInstance),
+	}, nil
 }
 
-// cbsVolume is a wrapper for TencentCloud CBS storage.
+// cbsVolume is a wrapper for TencentCloud CBS storage. Disks and instances are
+// cached for cacheTTL to avoid calling the cloud API for each collector.
 type cbsVolume struct {
+	client      tencentcloud.Client
+	cacheTTL    time.Duration
+	nodeIndexer cache.Indexer
+
+	lock      sync.Mutex
+	disks     map[string]*cachedDisk
+	instances map[string]*cachedInstance
+}
+
+// cachedDisk is a disk fetched from the cloud API, disk is nil if it doesn't exist.
+type cachedDisk struct {
+	disk      *tencentcloud.Disk
+	fetchTime time.Time
+}
+
+// cachedInstance is an instance fetched from the cloud API, instance is nil if it doesn't exist.
+type cachedInstance struct {
+	instance  *tencentcloud.Instance
+	fetchTime time.Time
 }
 
 // Start starts the manager.
@@ -37,19 +89,157 @@ func (v *cbsVolume) Start(stopCh <-chan struct{}) error {
 	return nil
 }
 
-// Status returns the getPVCStatus of a PVC/PV.
-func (v *cbsVolume) Available(w *storagev1alpha1.Workload, pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) error {
+// Available returns true if the volume can be mounted by a workload.
+func (v *cbsVolume) Available(
+	w *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
 	return blockVolumeAvailable(w, pvcr)
 }
 
-// MountedNodes returns the node list this volume mounted on.
-func (v *cbsVolume) MountedNodes(pv *corev1.PersistentVolume) ([]string, error) {
-	// TODO: Get information from Tencent Cloud API?
-	return nil, nil
+// MountedNodes returns the instance the disk attached to.
+func (v *cbsVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
+	disk, err := v.getDisk(pv.Spec.CSI.VolumeHandle)
+	if err != nil {
+		return nil, err
+	}
+	if disk == nil || !disk.Attached || len(disk.InstanceID) == 0 {
+		return nil, nil
+	}
+	instance, err := v.getInstance(disk.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The instance ID is used as the address if the instance has no private IP.
+	address := disk.InstanceID
+	if instance != nil && len(instance.PrivateIPAddresses) > 0 {
+		address = instance.PrivateIPAddresses[0]
+	}
+	nodeName, err := v.instanceNodeName(disk.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	return []storagev2.MountedNode{{
+		NodeName:   nodeName,
+		Address:    address,
+		AccessMode: storagev2.MountAccessReadWrite,
+	}}, nil
+}
+
+// Usage returns the real usage of volume in byte. The CBS API doesn't
+// know the usage of file systems, all fields are left to the kubelet volume stats.
+func (v *cbsVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	return &types.VolumeUsage{}, nil
+}
+
+// Attributes returns the type, zone and state of the disk.
+func (v *cbsVolume) Attributes(pv *corev1.PersistentVolume) (map[string]string, error) {
+	disk, err := v.getDisk(pv.Spec.CSI.VolumeHandle)
+	if err != nil {
+		return nil, err
+	}
+	if disk == nil {
+		return nil, nil
+	}
+	attributes := map[string]string{
+		"diskID":   disk.DiskID,
+		"diskType": disk.DiskType,
+		"zone":     disk.Placement.Zone,
+		"state":    disk.DiskState,
+	}
+	if len(disk.InstanceID) > 0 {
+		attributes["instanceID"] = disk.InstanceID
+	}
+	return attributes, nil
+}
+
+// getDisk returns a disk from the cache or the cloud API, nil if not exist.
+func (v *cbsVolume) getDisk(diskID string) (*tencentcloud.Disk, error) {
+	v.lock.Lock()
+	cached, exist := v.disks[diskID]
+	v.lock.Unlock()
+	if exist && time.Since(cached.fetchTime) < v.cacheTTL {
+		return cached.disk, nil
+	}
+
+	disks, err := v.client.DescribeDisks([]string{diskID})
+	if err != nil {
+		return nil, fmt.Errorf("describe disk %s failed: %v", diskID, err)
+	}
+	cached = &cachedDisk{fetchTime: time.Now()}
+	for i := range disks {
+		if disks[i].DiskID == diskID {
+			cached.disk = &disks[i]
+		}
+	}
+	v.lock.Lock()
+	v.disks[diskID] = cached
+	v.clearExpired()
+	v.lock.Unlock()
+
+	return cached.disk, nil
+}
+
+// getInstance returns an instance from the cache or the cloud API, nil if not exist.
+func (v *cbsVolume) getInstance(instanceID string) (*tencentcloud.Instance, error) {
+	v.lock.Lock()
+	cached, exist := v.instances[instanceID]
+	v.lock.Unlock()
+	if exist && time.Since(cached.fetchTime) < v.cacheTTL {
+		return cached.instance, nil
+	}
+
+	instances, err := v.client.DescribeInstances([]string{instanceID})
+	if err != nil {
+		return nil, fmt.Errorf("describe instance %s failed: %v", instanceID, err)
+	}
+	cached = &cachedInstance{fetchTime: time.Now()}
+	for i := range instances {
+		if instances[i].InstanceID == instanceID {
+			cached.instance = &instances[i]
+		}
+	}
+	v.lock.Lock()
+	v.instances[instanceID] = cached
+	v.clearExpired()
+	v.lock.Unlock()
+
+	return cached.instance, nil
+}
+
+// clearExpired removes expired disks and instances, the lock must be held.
+func (v *cbsVolume) clearExpired() {
+	for id, cached := range v.disks {
+		if time.Since(cached.fetchTime) >= v.cacheTTL {
+			delete(v.disks, id)
+		}
+	}
+	for id, cached := range v.instances {
+		if time.Since(cached.fetchTime) >= v.cacheTTL {
+			delete(v.instances, id)
+		}
+	}
+}
+
+// instanceNodeName returns the name of the node running on an instance, or an empty string if not found.
+func (v *cbsVolume) instanceNodeName(instanceID string) (string, error) {
+	objs, err := v.nodeIndexer.ByIndex(nodeInstanceIndex, instanceID)
+	if err != nil {
+		return "", fmt.Errorf("search node of instance %s failed: %v", instanceID, err)
+	}
+	if len(objs) == 0 {
+		return "", nil
+	}
+	return objs[0].(*corev1.Node).Name, nil
 }
 
-// Usage returns the real usage of volume in byte.
-func (v *cbsVolume) Usage(pv *corev1.PersistentVolume) (int64, error) {
-	// TODO
-	return 0, nil
+// indexNodeInstance indexes nodes by the CVM instance IDs in their providerIDs.
+func indexNodeInstance(obj interface{}) ([]string, error) {
+	node, ok := obj.(*corev1.Node)
+	if !ok || !strings.HasPrefix(node.Spec.ProviderID, qcloudProviderPrefix) {
+		return nil, nil
+	}
+	providerID := strings.TrimRight(node.Spec.ProviderID, "/")
+	return []string{providerID[strings.LastIndex(providerID, "/")+1:]}, nil
 }
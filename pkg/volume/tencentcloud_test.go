@@ -0,0 +1,165 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/config"
+	"tkestack.io/volume-decorator/pkg/tencentcloud"
+	"tkestack.io/volume-decorator/pkg/tencentcloud/fake"
+	"tkestack.io/volume-decorator/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newCBSTestVolume creates a cbsVolume calling server, with a node running on instance ins-1.
+func newCBSTestVolume(t *testing.T, server *fake.Server, cacheTTL time.Duration) *cbsVolume {
+	client := tencentcloud.NewClient(&config.TencentCloudConfig{
+		Region:      "ap-guangzhou",
+		CBSEndpoint: server.URL(),
+		CVMEndpoint: server.URL(),
+		SecretID:    "id",
+		SecretKey:   "key",
+	})
+	nodes := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0).Core().V1().Nodes()
+	v, err := newCBSVolume(client, cacheTTL, nodes)
+	if err != nil {
+		t.Fatalf("Create cbs volume failed: %v", err)
+	}
+	nodes.Informer().GetIndexer().Add(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{ProviderID: "qcloud:///800002/ins-1"},
+	})
+	return v.(*cbsVolume)
+}
+
+// newCBSTestPV creates a PV of a CBS disk.
+func newCBSTestPV(diskID string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-" + diskID},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: types.TencentCBS, VolumeHandle: diskID},
+			},
+		},
+	}
+}
+
+func TestCBSVolume(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	placement := tencentcloud.Placement{Zone: "ap-guangzhou-3"}
+	server.SetDisk(tencentcloud.Disk{DiskID: "disk-1", DiskType: "CLOUD_PREMIUM", DiskState: "ATTACHED",
+		Attached: true, InstanceID: "ins-1", Placement: placement})
+	server.SetDisk(tencentcloud.Disk{DiskID: "disk-2", DiskType: "CLOUD_SSD", DiskState: "ATTACHED",
+		Attached: true, InstanceID: "ins-2", Placement: placement})
+	server.SetDisk(tencentcloud.Disk{DiskID: "disk-3", DiskType: "CLOUD_BASIC", DiskState: "UNATTACHED",
+		Placement: placement})
+	server.SetInstance(tencentcloud.Instance{InstanceID: "ins-1", PrivateIPAddresses: []string{"10.0.0.1"}})
+	v := newCBSTestVolume(t, server, time.Minute)
+
+	for _, c := range []struct {
+		diskID     string
+		attributes map[string]string
+		nodes      []storagev2.MountedNode
+	}{
+		{
+			diskID: "disk-1",
+			attributes: map[string]string{"diskID": "disk-1", "diskType": "CLOUD_PREMIUM",
+				"zone": "ap-guangzhou-3", "state": "ATTACHED", "instanceID": "ins-1"},
+			nodes: []storagev2.MountedNode{
+				{NodeName: "node-1", Address: "10.0.0.1", AccessMode: storagev2.MountAccessReadWrite},
+			},
+		},
+		{
+			// The instance ID is the address of an instance unknown to the API and the cluster.
+			diskID: "disk-2",
+			attributes: map[string]string{"diskID": "disk-2", "diskType": "CLOUD_SSD",
+				"zone": "ap-guangzhou-3", "state": "ATTACHED", "instanceID": "ins-2"},
+			nodes: []storagev2.MountedNode{
+				{Address: "ins-2", AccessMode: storagev2.MountAccessReadWrite},
+			},
+		},
+		{
+			diskID: "disk-3",
+			attributes: map[string]string{"diskID": "disk-3", "diskType": "CLOUD_BASIC",
+				"zone": "ap-guangzhou-3", "state": "UNATTACHED"},
+		},
+		{
+			diskID: "disk-deleted",
+		},
+	} {
+		pv := newCBSTestPV(c.diskID)
+		attributes, err := v.Attributes(pv)
+		if err != nil {
+			t.Errorf("%s: get attributes failed: %v", c.diskID, err)
+		} else if !reflect.DeepEqual(attributes, c.attributes) {
+			t.Errorf("%s: expected attributes %v, got %v", c.diskID, c.attributes, attributes)
+		}
+		nodes, err := v.MountedNodes(pv)
+		if err != nil {
+			t.Errorf("%s: get mounted nodes failed: %v", c.diskID, err)
+		} else if !reflect.DeepEqual(nodes, c.nodes) {
+			t.Errorf("%s: expected nodes %v, got %v", c.diskID, c.nodes, nodes)
+		}
+	}
+
+	// Each disk and instance is described once in the cache TTL.
+	counts := make(map[string]int)
+	for _, action := range server.Actions() {
+		counts[action]++
+	}
+	if expected := map[string]int{"DescribeDisks": 4, "DescribeInstances": 2}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected actions %v, got %v", expected, counts)
+	}
+}
+
+func TestCBSVolumeAPIError(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.SetDisk(tencentcloud.Disk{DiskID: "disk-1", Attached: true, InstanceID: "ins-1"})
+	v := newCBSTestVolume(t, server, 0)
+	pv := newCBSTestPV("disk-1")
+
+	server.SetError("DescribeDisks", "RequestLimitExceeded", "too many requests")
+	if _, err := v.Attributes(pv); err == nil || !strings.Contains(err.Error(), "RequestLimitExceeded: too many requests") {
+		t.Errorf("Expected the API error of DescribeDisks, got %v", err)
+	}
+	if _, err := v.MountedNodes(pv); err == nil || !strings.Contains(err.Error(), "describe disk disk-1 failed") {
+		t.Errorf("Expected the API error of DescribeDisks, got %v", err)
+	}
+
+	server.SetError("DescribeDisks", "", "")
+	server.SetError("DescribeInstances", "AuthFailure.UnauthorizedOperation", "not authorized")
+	if _, err := v.MountedNodes(pv); err == nil ||
+		!strings.Contains(err.Error(), "describe instance ins-1 failed") ||
+		!strings.Contains(err.Error(), "AuthFailure.UnauthorizedOperation") {
+		t.Errorf("Expected the API error of DescribeInstances, got %v", err)
+	}
+	if _, err := v.Attributes(pv); err != nil {
+		t.Errorf("Get attributes failed: %v", err)
+	}
+}
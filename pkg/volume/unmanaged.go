@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package volume
+
+import (
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// unmanagedVolume is a volume not managed by the decorator. All workloads are admitted,
+// mounted nodes are unknown and usage is left to the kubelet volume stats.
+type unmanagedVolume struct{}
+
+// Start starts the volume.
+func (unmanagedVolume) Start(stopCh <-chan struct{}) error { return nil }
+
+// Available returns true if the volume can be mounted by a workload.
+func (unmanagedVolume) Available(
+	w *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
+	return nil
+}
+
+// MountedNodes returns the nodes mounted the volume.
+func (unmanagedVolume) MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error) {
+	return nil, nil
+}
+
+// Usage returns current usage of the volume.
+func (unmanagedVolume) Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error) {
+	return &types.VolumeUsage{}, nil
+}
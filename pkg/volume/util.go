@@ -18,17 +18,23 @@
 package volume
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/config"
+	"tkestack.io/volume-decorator/pkg/executor"
+	"tkestack.io/volume-decorator/pkg/util"
+
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
-	"path/filepath"
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
 )
 
 const (
@@ -36,47 +42,53 @@ const (
 	longCmdTimeout    = time.Minute * 5
 )
 
-// execCmd runs a cmd.
-func execCmd(timeout time.Duration, cmd string, args ...string) ([]byte, error) {
-	command := exec.Command(cmd, args...)
-	var stdout, stderr bytes.Buffer
-	command.Stdout = &stdout
-	command.Stderr = &stderr
-	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	if err := command.Start(); err != nil {
-		return nil, err
-	}
-
-	timer := time.AfterFunc(timeout, func() {
-		if err := syscall.Kill(-command.Process.Pid, syscall.SIGKILL); err != nil {
-			klog.Errorf("Kill process failed: %s %v, %v", cmd, args, err)
-		} else {
-			klog.Errorf("Execute command %s %v timeout(%ds): %d", cmd, args, timeout, command.Process.Pid)
-		}
-	})
-	defer timer.Stop()
+// commandConnectionFlags are the flags telling how to connect to a Ceph cluster, they
+// are not recorded since the paths of the config and keyring files are not fixed.
+var commandConnectionFlags = []string{"-c", "--keyring", "-m", "--id"}
 
-	err := command.Wait()
-	if err != nil {
-		if !isKilledErr(err) {
-			return nil, fmt.Errorf("execute cmd %s %v failed output: %s, error: %v", cmd, args, stderr.String(), err)
+// newCommandRunner creates a commandRunner running commands on the host, or serving
+// the outputs recorded in the replay dir. Outputs are recorded if the record dir is set.
+func newCommandRunner(cfg *config.VolumeConfig) (*commandRunner, error) {
+	var runner executor.Executor
+	if len(cfg.CommandReplayDir) > 0 {
+		runner = executor.NewReplayer(cfg.CommandReplayDir, commandConnectionFlags...)
+	} else {
+		runner = executor.New()
+	}
+	if len(cfg.CommandRecordDir) > 0 {
+		recorder, err := executor.NewRecorder(runner, cfg.CommandRecordDir, commandConnectionFlags...)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("execute command(%s %v) timeout with error(%v)", cmd, args, err)
+		runner = recorder
 	}
-	return stdout.Bytes(), nil
+	ctx, cancel := context.WithCancel(context.Background())
+	return &commandRunner{executor: runner, ctx: ctx, cancel: cancel}, nil
 }
 
-// isKilledErr returns true if an error is a SIGKILL error.
-func isKilledErr(err error) bool {
-	if strings.Contains(err.Error(), "signal: killed") {
-		return true
-	}
-	return false
+// commandRunner runs the commands of a volume, the running commands are killed once the volume stopped.
+type commandRunner struct {
+	executor executor.Executor
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// stopOn cancels the commands when stopCh closed.
+func (r *commandRunner) stopOn(stopCh <-chan struct{}) {
+	go func() {
+		<-stopCh
+		r.cancel()
+	}()
+}
+
+// execCmd runs a cmd.
+func (r *commandRunner) execCmd(timeout time.Duration, cmd string, args ...string) ([]byte, error) {
+	return r.executor.Exec(r.ctx, timeout, cmd, args...)
 }
 
 // execCommand runs a command.
-func execCommand(command string, args []string) ([]byte, error) {
-	return execCmd(defaultCmdTimeout, command, args...)
+func (r *commandRunner) execCommand(command string, args []string) ([]byte, error) {
+	return r.execCmd(defaultCmdTimeout, command, args...)
 }
 
 // parseAddress extract IP from an IP:Port address.
@@ -84,17 +96,132 @@ func parseAddress(address string) string {
 	return address[:strings.Index(address, ":")]
 }
 
+// parseClientInst extracts IP from a Ceph client instance like "client.4305 v1:10.0.0.1:0/3251934".
+func parseClientInst(inst string) string {
+	fields := strings.Fields(inst)
+	if len(fields) != 2 {
+		return ""
+	}
+	address := fields[1]
+	if i := strings.Index(address, ":"); i > 0 && strings.HasPrefix(address, "v") {
+		if _, err := strconv.Atoi(address[1:i]); err == nil {
+			address = address[i+1:]
+		}
+	}
+	if !strings.Contains(address, ":") {
+		return ""
+	}
+	return parseAddress(address)
+}
+
 // isRBDImageNotFound returns true if an error is a RBDImageNotFound error.
 func isRBDImageNotFound(err error) bool {
 	return strings.Contains(err.Error(), "No such file or directory")
 }
 
-// getCephfsPath extracts cephfs path from a PV object.
-func getCephfsPath(pv *corev1.PersistentVolume) string {
-	return filepath.Join(cephfsVolumesRoot, pv.Spec.CSI.VolumeHandle)
+// nodeAddress returns the InternalIP of a node, or the node name if it has no InternalIP.
+func nodeAddress(nodeLister corelisters.NodeLister, nodeName string) (string, error) {
+	node, err := nodeLister.Get(nodeName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			klog.V(4).Infof("Node %s not exist", nodeName)
+			return nodeName, nil
+		}
+		return "", fmt.Errorf("get node %s failed: %v", nodeName, err)
+	}
+	for _, a := range node.Status.Addresses {
+		if a.Type == corev1.NodeInternalIP {
+			return a.Address, nil
+		}
+	}
+	return nodeName, nil
 }
 
-// sameWorkload returns true if to workload is same.
-func sameWorkload(w1, w2 *storagev1alpha1.Workload) bool {
-	return w1.ObjectReference.String() == w2.ObjectReference.String()
+// podMountedNodes returns the nodes of the scheduled pods using a volume. A node mounts
+// the volume as ReadWrite if any pod on it does, unless the volume is read only.
+func podMountedNodes(
+	podIndexer cache.Indexer,
+	nodeLister corelisters.NodeLister,
+	pv *corev1.PersistentVolume,
+	readOnly bool) ([]storagev2.MountedNode, error) {
+	claim := pv.Spec.ClaimRef
+	if claim == nil {
+		return nil, nil
+	}
+	objs, err := podIndexer.ByIndex(util.PodClaimIndex, claim.Namespace+"/"+claim.Name)
+	if err != nil {
+		return nil, fmt.Errorf("search pods of PVC %s/%s failed: %v", claim.Namespace, claim.Name, err)
+	}
+
+	accessModes := make(map[string]storagev2.MountAccessMode)
+	for _, obj := range objs {
+		pod := obj.(*corev1.Pod)
+		if len(pod.Spec.NodeName) == 0 ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		mode := storagev2.MountAccessReadOnly
+		if !readOnly && !podClaimReadOnly(pod, claim.Name) {
+			mode = storagev2.MountAccessReadWrite
+		}
+		if accessModes[pod.Spec.NodeName] != storagev2.MountAccessReadWrite {
+			accessModes[pod.Spec.NodeName] = mode
+		}
+	}
+
+	nodes := make([]storagev2.MountedNode, 0, len(accessModes))
+	for nodeName, mode := range accessModes {
+		address, err := nodeAddress(nodeLister, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, storagev2.MountedNode{NodeName: nodeName, Address: address, AccessMode: mode})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Address < nodes[j].Address })
+
+	return nodes, nil
+}
+
+// podClaimReadOnly returns true if a pod mounts the PVC as read only in all its volumes.
+func podClaimReadOnly(pod *corev1.Pod, claimName string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		source := volume.PersistentVolumeClaim
+		if source != nil && source.ClaimName == claimName && !source.ReadOnly {
+			return false
+		}
+	}
+	return true
+}
+
+// getCephfsClusterID returns the ceph-csi clusterID of a CephFS PV, or an empty string for the default cluster.
+func getCephfsClusterID(pv *corev1.PersistentVolume) string {
+	if pv.Spec.CSI == nil {
+		return ""
+	}
+	return pv.Spec.CSI.VolumeAttributes["clusterID"]
+}
+
+// csiSecretRef returns the Secret of a CSI volume to access the storage, nodeStageSecretRef
+// is preferred over controllerExpandSecretRef.
+func csiSecretRef(source *corev1.CSIPersistentVolumeSource) *corev1.SecretReference {
+	if source.NodeStageSecretRef != nil {
+		return source.NodeStageSecretRef
+	}
+	return source.ControllerExpandSecretRef
+}
+
+// cephfsSecretRef returns the Secret and the user of a CephFS volume to manage its subvolume.
+// controllerExpandSecretRef of a CSI volume is preferred, since the subvolume commands need the
+// caps of the provisioner rather than the ones of the mounting user.
+func cephfsSecretRef(pv *corev1.PersistentVolume) (*corev1.SecretReference, string) {
+	if source := pv.Spec.CephFS; source != nil {
+		return source.SecretRef, source.User
+	}
+	if source := pv.Spec.CSI; source != nil {
+		if source.ControllerExpandSecretRef != nil {
+			return source.ControllerExpandSecretRef, ""
+		}
+		return source.NodeStageSecretRef, ""
+	}
+	return nil, ""
 }
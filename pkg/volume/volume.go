@@ -22,7 +22,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	storagev1alpha1 "tkestack.io/volume-decorator/pkg/apis/storage/v1"
+	storagev2 "tkestack.io/volume-decorator/pkg/apis/storage/v2"
+	"tkestack.io/volume-decorator/pkg/types"
 )
 
 // volume provides a unified view for different volume types.
@@ -30,17 +31,40 @@ type volume interface {
 	// Start starts the volume.
 	Start(stopCh <-chan struct{}) error
 	// Available returns true if the volume can be mounted by a workload.
-	Available(w *storagev1alpha1.Workload, pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) error
-	// MountedNodes returns the workloads mounted the volume.
-	MountedNodes(pv *corev1.PersistentVolume) ([]string, error)
-	// Usage returns current usage of the volume.
-	Usage(pv *corev1.PersistentVolume) (int64, error)
+	Available(w *storagev2.Workload, pv *corev1.PersistentVolume, pvcr *storagev2.PersistentVolumeClaimRuntime) error
+	// MountedNodes returns the nodes mounted the volume, only NodeName, Address and AccessMode
+	// are filled, NodeName is left empty if the backend doesn't know it.
+	MountedNodes(pv *corev1.PersistentVolume) ([]storagev2.MountedNode, error)
+	// Usage returns current usage of the volume, unknown fields are left zero.
+	Usage(pv *corev1.PersistentVolume) (*types.VolumeUsage, error)
+}
+
+// attributesGetter is implemented by the volumes which know backend specific attributes.
+type attributesGetter interface {
+	// Attributes returns the backend specific attributes of the volume.
+	Attributes(pv *corev1.PersistentVolume) (map[string]string, error)
+}
+
+// quotaManager is implemented by the volumes whose quota is enforced by the storage backend,
+// and can be set by the decorator.
+type quotaManager interface {
+	// Quota returns the quota of the volume in bytes, 0 if not set, or nil if the quota of the
+	// volume is not managed, such as a volume not provisioned by the decorated provisioner.
+	Quota(pv *corev1.PersistentVolume) (*int64, error)
+	// SetQuota sets the quota of the volume in bytes.
+	SetQuota(pv *corev1.PersistentVolume, bytes int64) error
+}
+
+// schedulingChecker is implemented by the volumes which are only accessible on some nodes.
+type schedulingChecker interface {
+	// Schedulable returns an error if pods of a workload can't be scheduled to the nodes the volume is accessible on.
+	Schedulable(pv *corev1.PersistentVolume, podSpecs []*corev1.PodSpec) error
 }
 
 // blockVolumeAvailable returns true if a block storage is available.
 func blockVolumeAvailable(
-	workload *storagev1alpha1.Workload,
-	pvcr *storagev1alpha1.PersistentVolumeClaimRuntime) error {
+	workload *storagev2.Workload,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
 	if workload.ReadOnly {
 		return nil
 	}
@@ -49,7 +73,7 @@ func blockVolumeAvailable(
 			fmt.Sprintf("CephRBD volume cannot be mounted as ReadWrite mode by workloads with %d replicas",
 				*workload.Replicas))
 	}
-	for _, w := range pvcr.Spec.Workloads {
+	for _, w := range pvcr.Status.Workloads {
 		if !w.ReadOnly {
 			return k8serrors.NewBadRequest(
 				"CephRBD volume cannot be mounted as ReadWrite mode by more than one workload")
@@ -57,3 +81,39 @@ func blockVolumeAvailable(
 	}
 	return nil
 }
+
+// accessModesAvailable returns true if the access modes of a PV allow it to be mounted by a workload.
+// A ReadWriteOnce volume is treated as exclusive since workloads may be scheduled to different nodes.
+func accessModesAvailable(
+	workload *storagev2.Workload,
+	pv *corev1.PersistentVolume,
+	pvcr *storagev2.PersistentVolumeClaimRuntime) error {
+	if hasAccessMode(pv, corev1.ReadWriteMany) {
+		return nil
+	}
+	if workload.ReadOnly && hasAccessMode(pv, corev1.ReadOnlyMany) {
+		return nil
+	}
+	if !hasAccessMode(pv, corev1.ReadWriteOnce) {
+		return k8serrors.NewBadRequest(
+			fmt.Sprintf("volume with access modes %v cannot be mounted as ReadWrite mode", pv.Spec.AccessModes))
+	}
+	if workload.Replicas != nil && *workload.Replicas > 1 {
+		return k8serrors.NewBadRequest(
+			fmt.Sprintf("ReadWriteOnce volume cannot be mounted by workloads with %d replicas", *workload.Replicas))
+	}
+	if len(pvcr.Status.Workloads) > 0 {
+		return k8serrors.NewBadRequest("ReadWriteOnce volume cannot be mounted by more than one workload")
+	}
+	return nil
+}
+
+// hasAccessMode returns true if a PV supports the access mode.
+func hasAccessMode(pv *corev1.PersistentVolume, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range pv.Spec.AccessModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
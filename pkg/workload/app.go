@@ -236,6 +236,7 @@ func (m *appManager) getWorkloadAndPodSpec(obj runtime.Object) (*Workload, *core
 			UID:        accessor.GetUID(),
 		},
 		Replicas: replicas,
+		PodSpecs: []*corev1.PodSpec{podSpec},
 	}, podSpec, nil
 }
 
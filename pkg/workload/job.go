@@ -91,7 +91,11 @@ func (m *jobManager) Handle(
 	}
 	klog.V(4).Infof("Processed app: %+v", ref)
 
-	return &Workload{ObjectReference: ref, Replicas: job.Spec.Parallelism}, usedVolumes, releasedVolumes, nil
+	return &Workload{
+		ObjectReference: ref,
+		Replicas:        job.Spec.Parallelism,
+		PodSpecs:        []*corev1.PodSpec{&job.Spec.Template.Spec},
+	}, usedVolumes, releasedVolumes, nil
 }
 
 // MountedVolumes returns mounted volumes by a workload.
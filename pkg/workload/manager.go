@@ -28,7 +28,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/kubernetes"
 	"tkestack.io/tapp/pkg/apis/tappcontroller"
 	tappv1 "tkestack.io/tapp/pkg/apis/tappcontroller/v1"
 )
@@ -47,7 +46,6 @@ type Manager interface {
 
 // New creates a new Manager.
 func New(
-	k8sClient kubernetes.Interface,
 	informerFactory informers.SharedInformerFactory,
 	tappManager tapps.Manager) Manager {
 	podGVK := metav1.GroupVersionKind{
@@ -88,7 +86,7 @@ func New(
 
 	manager := &compositeManager{
 		managers: map[metav1.GroupVersionKind]Manager{
-			podGVK:         newPodManager(k8sClient),
+			podGVK:         newPodManager(informerFactory),
 			deploymentGVK:  newDeploymentManager(informerFactory),
 			replicaSetGVK:  newReplicaSetManager(informerFactory),
 			statefulSetGVK: newStatefulSetManager(informerFactory),
@@ -163,6 +161,8 @@ type VolumeInfo = corev1.PersistentVolumeClaimVolumeSource
 type Workload struct {
 	corev1.ObjectReference
 	Replicas *int32
+	// PodSpecs are the specs of pods created by the workload, used to check scheduling constraints.
+	PodSpecs []*corev1.PodSpec
 }
 
 // newIgnoreError returns an error which can be ignored by invokers.
@@ -25,23 +25,32 @@ import (
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 )
 
 // newPodManager creates a Manager for k8s native Pod API.
-func newPodManager(k8sClient kubernetes.Interface) Manager {
-	return &podManager{k8sClient: k8sClient}
+func newPodManager(informerFactory informers.SharedInformerFactory) Manager {
+	informer := informerFactory.Core().V1().Pods()
+	return &podManager{
+		podLister: informer.Lister(),
+		podSynced: informer.Informer().HasSynced,
+	}
 }
 
 // podManager is a Manager for k8s native Pod API.
 type podManager struct {
-	k8sClient kubernetes.Interface
+	podLister corelisters.PodLister
+	podSynced cache.InformerSynced
 }
 
 // Start starts the manager.
 func (m *podManager) Start(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, m.podSynced) {
+		return fmt.Errorf("wait for Pod caches synced timeout")
+	}
 	return nil
 }
 
@@ -81,16 +90,17 @@ func (m *podManager) Handle(
 	ref := corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID}
 	klog.V(4).Infof("Processed Pod: %+v", ref)
 
-	return &Workload{ObjectReference: ref, Replicas: int32Ptr(1)}, usedVolumes, releasedVolumes, nil
+	return &Workload{
+		ObjectReference: ref,
+		Replicas:        int32Ptr(1),
+		PodSpecs:        []*corev1.PodSpec{&pod.Spec},
+	}, usedVolumes, releasedVolumes, nil
 }
 
 // MountedVolumes returns mounted volumes by a workload.
 func (m *podManager) MountedVolumes(ref *corev1.ObjectReference) ([]*VolumeInfo, error) {
-	// NOTE: Get the pod from Apiserver directly. We only concern independent pods not created
-	// by any controllers, and suppose there aren't many pods of this type. The benefit is that
-	// we needn't to cache all pods in the informer, this may take up a lot of memory.
-	// If this becomes a performance bottleneck maybe we still need to use informer instead.
-	pod, err := m.k8sClient.CoreV1().Pods(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+	// NOTE: All pods are cached since the consuming pods of volumes are collected from the informer.
+	pod, err := m.podLister.Pods(ref.Namespace).Get(ref.Name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			klog.V(4).Infof("Pod %s/%s not exist", ref.Namespace, ref.Name)
@@ -108,7 +118,7 @@ func (m *podManager) MountedVolumes(ref *corev1.ObjectReference) ([]*VolumeInfo,
 
 // Exist returns true is a workload exist.
 func (m *podManager) Exist(ref *corev1.ObjectReference) (bool, error) {
-	_, err := m.k8sClient.CoreV1().Pods(ref.Namespace).Get(ref.Name, metav1.GetOptions{})
+	_, err := m.podLister.Pods(ref.Namespace).Get(ref.Name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			klog.V(4).Infof("Pod %s not exist", ref.String())
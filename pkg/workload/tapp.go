@@ -89,7 +89,11 @@ func (m *tappManager) Handle(
 	}
 	klog.V(4).Infof("Processed Tapp: %+v", ref)
 
-	return &Workload{ObjectReference: ref, Replicas: int32Ptr(1)}, usedVolumes, releasedVolumes, nil
+	return &Workload{
+		ObjectReference: ref,
+		Replicas:        int32Ptr(1),
+		PodSpecs:        extractTappPodSpecs(tapp),
+	}, usedVolumes, releasedVolumes, nil
 }
 
 // MountedVolumes returns mounted volumes by a workload.